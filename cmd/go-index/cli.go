@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultConfigFile = "config.yml"
+
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(args []string, stdout, stderr io.Writer) int
+}
+
+func commands() []command {
+	return []command{
+		{"serve", "serve [flags]", "Run the HTTP server", runServe},
+		{"versions", "versions [flags] <repository> <module> <artifact>", "List the versions of an artifact",
+			runVersions},
+		{"latest", "latest [flags] <repository> <module> <artifact>", "Print the latest version of an artifact",
+			runLatest},
+		{"resolve", "resolve [flags] <repository> <module> <artifact> <constraint>",
+			"Print the highest version matching a constraint", runResolve},
+		{"validate", "validate [flags] [file]", "Validate a configuration file", runValidate},
+		{"export", "export [flags]", "Print the effective configuration with secrets redacted", runExport},
+	}
+}
+
+// run dispatches to a subcommand. Without one it serves, and a lone config path is accepted for
+// compatibility with go-index <config>.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		return runServe(nil, stdout, stderr)
+	}
+
+	for _, command := range commands() {
+		if args[0] == command.name {
+			return command.run(args[1:], stdout, stderr)
+		}
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage(stdout)
+
+		return exitOK
+	}
+
+	if len(args) == 1 && !strings.HasPrefix(args[0], "-") {
+		if _, err := os.Stat(args[0]); err == nil {
+			return runServe([]string{"-config", args[0]}, stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "Unknown command %q\n\n", args[0])
+	printUsage(stderr)
+
+	return exitUsage
+}
+
+func printUsage(writer io.Writer) {
+	fmt.Fprintln(writer, "Usage: go-index <command> [flags]")
+	fmt.Fprintln(writer, "\nCommands:")
+
+	for _, command := range commands() {
+		fmt.Fprintf(writer, "  %-10s %s\n", command.name, command.description)
+	}
+
+	fmt.Fprintln(writer, "\nRun go-index <command> -h for the flags of a command.")
+}
+
+// options are the flags shared by the subcommands.
+type options struct {
+	configFile string
+	logLevel   string
+	output     string
+}
+
+// newFlagSet registers the shared flags; outputs lists the accepted -output values, the first being the
+// default, or is empty when the command has a single format.
+func newFlagSet(name string, stderr io.Writer, outputs ...string) (*flag.FlagSet, *options) {
+	var opts options
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.configFile, "config", defaultConfigFile, "configuration file")
+	flags.StringVar(&opts.logLevel, "log-level", "warn",
+		"log level: trace, debug, info, warn, error or fatal")
+
+	if len(outputs) > 0 {
+		flags.StringVar(&opts.output, "output", outputs[0], "output format: "+strings.Join(outputs, " or "))
+	}
+
+	for _, command := range commands() {
+		if command.name == name {
+			flags.Usage = func() {
+				fmt.Fprintf(stderr, "Usage: go-index %s\n\n%s.\n\nFlags:\n", command.usage, command.description)
+				flags.PrintDefaults()
+			}
+		}
+	}
+
+	return flags, &opts
+}
+
+// parseFlags parses args and checks the positional argument count and the output format, returning an
+// exit code when the command should stop.
+func parseFlags(flags *flag.FlagSet, opts *options, args []string, positional int, outputs ...string) (int, bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+
+		return exitUsage, false
+	}
+
+	if positional >= 0 && flags.NArg() != positional {
+		flags.Usage()
+
+		return exitUsage, false
+	}
+
+	if len(outputs) > 0 && !slices.Contains(outputs, opts.output) {
+		fmt.Fprintf(flags.Output(), "Invalid -output %q, want %s\n", opts.output, strings.Join(outputs, " or "))
+
+		return exitUsage, false
+	}
+
+	return exitOK, true
+}
+
+func newLogger(level string, output io.Writer) (*logrus.Logger, error) {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(output)
+	logger.SetFormatter(&logrus.JSONFormatter{
+		TimestampFormat:   time.RFC3339,
+		DisableTimestamp:  false,
+		DisableHTMLEscape: false,
+		DataKey:           "data",
+		FieldMap:          logrus.FieldMap{},
+		CallerPrettyfier:  nil,
+		PrettyPrint:       false,
+	})
+	logger.SetLevel(parsed)
+	logger.SetReportCaller(true)
+
+	return logger, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeCLIConfig(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for _, version := range []string{"1.0.0", "1.2.0", "2.0.0", "latest"} {
+		filename := filepath.Join(dir, "data", "fe", "app1", "app1-"+version+".txt")
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte(version), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", filename, err)
+		}
+	}
+
+	configFile := filepath.Join(dir, "config.yml")
+	content := "repositories:\n  - name: files\n    provider: local\n  - name: s3files\n    provider: s3\n" +
+		"providers:\n  local:\n    type: local\n    path: " + filepath.Join(dir, "data") + "\n" +
+		"  s3:\n    type: s3\n    bucket: artifacts\n    accessKey: 9wnheHR37PwXdE8YF56U\n    secretKey: MzTDkSsvcJgHAoSU2D4Z7\n" +
+		"auth:\n  users:\n    - name: ci\n      tokenHashes: [\"sha256:" + strings.Repeat("ab", 32) + "\"]\n"
+
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	return configFile
+}
+
+func TestRunQueries(t *testing.T) {
+	t.Parallel()
+
+	configFile := writeCLIConfig(t)
+
+	testCases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"versions", "-config", configFile, "files", "fe", "app1"}, "fe      app1      2.0.0"},
+		{[]string{"latest", "-config", configFile, "-output", "json", "files", "fe", "app1"},
+			`"version": "2.0.0"`},
+		{[]string{"latest", "-config", configFile, "files", "fe", "app1"}, "latest"},
+		{[]string{"resolve", "-config", configFile, "-output", "json", "files", "fe", "app1", "^1.0.0"},
+			`"version": "1.2.0"`},
+	}
+
+	for _, testCase := range testCases {
+		var stdout, stderr bytes.Buffer
+
+		if code := run(testCase.args, &stdout, &stderr); code != exitOK {
+			t.Errorf("%v returned %d: %s", testCase.args, code, stderr.String())
+
+			continue
+		}
+
+		if !strings.Contains(stdout.String(), testCase.want) {
+			t.Errorf("%v printed %q; want it to contain %q", testCase.args, stdout.String(), testCase.want)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Parallel()
+
+	configFile := writeCLIConfig(t)
+
+	testCases := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"unknown"}, exitUsage},
+		{[]string{"versions", "-config", configFile, "files", "fe"}, exitUsage},
+		{[]string{"versions", "-config", configFile, "-output", "xml", "files", "fe", "app1"}, exitUsage},
+		{[]string{"versions", "-config", configFile, "-log-level", "loud", "files", "fe", "app1"}, exitUsage},
+		{[]string{"versions", "-config", configFile, "missing", "fe", "app1"}, exitFailure},
+		{[]string{"resolve", "-config", configFile, "files", "fe", "app1", "^9.0.0"}, exitFailure},
+	}
+
+	for _, testCase := range testCases {
+		var stdout, stderr bytes.Buffer
+
+		if code := run(testCase.args, &stdout, &stderr); code != testCase.want {
+			t.Errorf("%v returned %d; want %d", testCase.args, code, testCase.want)
+		}
+	}
+}
+
+func TestRunExport(t *testing.T) {
+	t.Parallel()
+
+	configFile := writeCLIConfig(t)
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run([]string{"export", "-config", configFile, "-output", "json"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("export returned %d: %s", code, stderr.String())
+	}
+
+	var exported struct {
+		Repositories []struct {
+			Name string `json:"name"`
+		} `json:"repositories"`
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &exported); err != nil || len(exported.Repositories) != 2 {
+		t.Errorf("export printed %s; want both repositories as JSON", stdout.String())
+	}
+
+	for _, secret := range []string{"MzTDkSsvcJgHAoSU2D4Z7", "9wnheHR37PwXdE8YF56U", strings.Repeat("ab", 32)} {
+		if strings.Contains(stdout.String(), secret) {
+			t.Errorf("export printed the credential %s", secret)
+		}
+	}
+
+	stdout.Reset()
+
+	if code := run([]string{"export", "-config", configFile}, &stdout, &stderr); code != exitOK ||
+		!strings.Contains(stdout.String(), "secretKey: '[REDACTED]'") {
+		t.Errorf("export returned %d with %q; want YAML with the secret redacted", code, stdout.String())
+	}
+}
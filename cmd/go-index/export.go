@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mauhlik/go-index/config"
+	"gopkg.in/yaml.v2"
+)
+
+// runExport prints the configuration as loaded, with variables and secret files expanded and secrets
+// redacted, so operators can check what the server will run with.
+func runExport(args []string, stdout, stderr io.Writer) int {
+	flags, opts := newFlagSet("export", stderr, outputYAML, outputJSON)
+	if code, ok := parseFlags(flags, opts, args, 0, outputYAML, outputJSON); !ok {
+		return code
+	}
+
+	cfg, err := config.LoadConfig(opts.configFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load config: %v\n", err)
+
+		return exitFailure
+	}
+
+	if opts.output == outputJSON {
+		err = writeJSON(stdout, cfg)
+	} else {
+		err = yaml.NewEncoder(stdout).Encode(cfg)
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to export config: %v\n", err)
+
+		return exitFailure
+	}
+
+	return exitOK
+}
@@ -1,107 +1,87 @@
 package main
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"net"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
-	"github.com/gin-gonic/gin"
 	"github.com/mauhlik/go-index/config"
-	"github.com/mauhlik/go-index/internal/go-index/controllers"
-	"github.com/mauhlik/go-index/internal/go-index/providers"
-	"github.com/mauhlik/go-index/internal/go-index/services"
-	"github.com/sirupsen/logrus"
-)
-
-var (
-	ErrProviderNotFound    = errors.New("provider not found")
-	ErrUnknownProviderType = errors.New("unknown provider type")
+	"github.com/mauhlik/go-index/internal/go-index/metrics"
+	"github.com/mauhlik/go-index/internal/go-index/server"
 )
 
 func main() {
-	configFile := "config.yml"
-	if len(os.Args) > 1 {
-		configFile = os.Args[1]
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func runServe(args []string, _, stderr io.Writer) int {
+	flags, opts := newFlagSet("serve", stderr)
+	if code, ok := parseFlags(flags, opts, args, 0); !ok {
+		return code
 	}
 
-	cfg, err := config.LoadConfig(configFile)
+	cfg, err := config.LoadConfig(opts.configFile)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fmt.Fprintf(stderr, "Failed to load config: %v\n", err)
+
+		return exitFailure
 	}
 
-	port := cfg.Port
-	if port == "" {
-		port = "8080"
+	logger, err := newLogger(opts.logLevel, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitUsage
 	}
 
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat:   time.RFC3339,
-		DisableTimestamp:  false,
-		DisableHTMLEscape: false,
-		DataKey:           "data",
-		FieldMap:          logrus.FieldMap{},
-		CallerPrettyfier:  nil,
-		PrettyPrint:       false,
-	})
-	logger.SetLevel(logrus.WarnLevel)
-	logger.SetReportCaller(true)
+	handler, err := server.NewHandler(cfg, metrics.New(), logger)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to register routes: %v\n", err)
 
-	router := gin.Default()
+		return exitFailure
+	}
 
-	registerRoutes(router, cfg, logger)
+	defer handler.Close()
 
-	logger.Infof("Starting server on port %s", port)
+	httpServer, err := newHTTPServer(cfg, handler, logger)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to setup server: %v\n", err)
 
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal(err)
+		return exitFailure
 	}
-}
 
-func registerRoutes(router *gin.Engine, cfg *config.Config, logger *logrus.Logger) {
-	for _, repo := range cfg.Repositories {
-		provider, err := setupProviderForRepository(cfg, repo, logger)
-		if err != nil {
-			log.Fatalf("Failed to setup provider for repository %s: %v", repo.Name, err)
-		}
+	listener, err := net.Listen("tcp", httpServer.Addr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to listen on %s: %v\n", httpServer.Addr, err)
 
-		versionService := services.NewService(provider, logger)
-		versionController := controllers.NewVersionController(versionService, logger)
-		group := router.Group("/api/" + repo.Name)
-		{
-			group.GET("/:module/:artifact/versions", versionController.GetVersions)
-			group.GET("/:module/:artifact/versions/latest", versionController.GetLatestVersion)
-		}
+		return exitFailure
 	}
-}
 
-//nolint:ireturn
-func setupProviderForRepository(cfg *config.Config, repo config.RepositoryConfig,
-	logger *logrus.Logger) (providers.Provider, error) {
-	providerConfig, ok := cfg.Providers[repo.Provider]
-	if !ok {
-		return nil, fmt.Errorf("%w for repository %s: %s", ErrProviderNotFound, repo.Name, repo.Provider)
-	}
+	logger.Infof("Starting server on %s", httpServer.Addr)
 
-	var provider providers.Provider
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	var err error
+	go watchConfig(ctx, opts.configFile, cfg.Reload.Interval.Duration(), logger, func() {
+		updated, err := config.LoadConfig(opts.configFile)
+		if err == nil {
+			err = handler.Reload(updated)
+		}
 
-	switch conf := providerConfig.(type) {
-	case config.LocalProviderConfig:
-		provider = providers.NewLocalProvider(conf.Path)
-	case config.S3ProviderConfig:
-		provider, err = providers.NewS3Provider(
-			conf.Bucket, conf.Endpoint, conf.AccessKey, conf.SecretKey, conf.Region, logger,
-		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to initialize S3 provider for repository %s: %w", repo.Name, err)
+			logger.WithError(err).Error("Failed to reload configuration, keeping the previous one")
 		}
-	default:
-		return nil, fmt.Errorf("%w for repository %s", ErrUnknownProviderType, repo.Name)
+	})
+
+	if err := serve(ctx, httpServer, listener, cfg.Server.ShutdownTimeout.Duration(), logger); err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
 	}
 
-	return provider, nil
+	return exitOK
 }
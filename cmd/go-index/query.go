@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mauhlik/go-index/config"
+	"github.com/mauhlik/go-index/internal/go-index/server"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+)
+
+// query is a versions, latest or resolve invocation: the repository and artifact it targets and the
+// service of that repository.
+type query struct {
+	service  services.VersionService
+	module   string
+	artifact string
+	output   string
+	extra    []string
+}
+
+func runVersions(args []string, stdout, stderr io.Writer) int {
+	return runQuery("versions", args, 0, stdout, stderr, func(ctx context.Context, q query) error {
+		versions, err := q.service.GetVersions(ctx, q.module, q.artifact)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if q.output == outputJSON {
+			return writeJSON(stdout, versions)
+		}
+
+		rows := make([][]string, 0, len(versions))
+		for _, version := range versions {
+			rows = append(rows, []string{q.module, q.artifact, version})
+		}
+
+		return writeTable(stdout, []string{"MODULE", "ARTIFACT", "VERSION"}, rows)
+	})
+}
+
+func runLatest(args []string, stdout, stderr io.Writer) int {
+	return runQuery("latest", args, 0, stdout, stderr, func(ctx context.Context, q query) error {
+		resolution, err := q.service.GetLatestVersion(ctx, q.module, q.artifact)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return writeResolution(stdout, q, resolution)
+	})
+}
+
+func runResolve(args []string, stdout, stderr io.Writer) int {
+	return runQuery("resolve", args, 1, stdout, stderr, func(ctx context.Context, q query) error {
+		resolution, err := q.service.ResolveVersion(ctx, q.module, q.artifact, q.extra[0])
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return writeResolution(stdout, q, resolution)
+	})
+}
+
+// runQuery parses <repository> <module> <artifact> followed by extra arguments, builds the service of
+// the repository and runs do, bounded by the repository timeout.
+func runQuery(name string, args []string, extra int, stdout, stderr io.Writer,
+	do func(ctx context.Context, q query) error) int {
+	flags, opts := newFlagSet(name, stderr, outputTable, outputJSON)
+	if code, ok := parseFlags(flags, opts, args, 3+extra, outputTable, outputJSON); !ok {
+		return code
+	}
+
+	cfg, err := config.LoadConfig(opts.configFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load config: %v\n", err)
+
+		return exitFailure
+	}
+
+	logger, err := newLogger(opts.logLevel, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitUsage
+	}
+
+	repository := flags.Arg(0)
+
+	service, err := server.NewVersionService(cfg, repository, logger)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for _, repo := range cfg.Repositories {
+		if repo.Name == repository && repo.Timeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, repo.Timeout.Duration())
+			defer cancel()
+		}
+	}
+
+	err = do(ctx, query{
+		service:  service,
+		module:   flags.Arg(1),
+		artifact: flags.Arg(2),
+		output:   opts.output,
+		extra:    flags.Args()[3:],
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func writeResolution(stdout io.Writer, q query, resolution *services.Resolution) error {
+	if q.output == outputJSON {
+		return writeJSON(stdout, resolution)
+	}
+
+	return writeTable(stdout, []string{"MODULE", "ARTIFACT", "VERSION", "SKIPPED"},
+		[][]string{{q.module, q.artifact, resolution.Version, strings.Join(resolution.Skipped, ",")}})
+}
+
+func writeJSON(stdout io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	return nil
+}
+
+func writeTable(stdout io.Writer, header []string, rows [][]string) error {
+	writer := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write table: %w", err)
+	}
+
+	return nil
+}
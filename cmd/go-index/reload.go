@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const defaultReloadInterval = 5 * time.Second
+
+// watchConfig calls reload on SIGHUP and whenever the size or modification time of filename changes,
+// checked every interval, until ctx is done.
+func watchConfig(ctx context.Context, filename string, interval time.Duration, logger *logrus.Logger,
+	reload func()) {
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+
+	defer signal.Stop(hangups)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	stamp, _ := fileStamp(filename)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangups:
+			logger.Info("Received SIGHUP, reloading configuration")
+		case <-ticker.C:
+			current, err := fileStamp(filename)
+			if err != nil || current == stamp {
+				continue
+			}
+
+			logger.Infof("Configuration file %s changed, reloading", filename)
+		}
+
+		stamp, _ = fileStamp(filename)
+
+		reload()
+	}
+}
+
+func fileStamp(filename string) (string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", filename, err)
+	}
+
+	return fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano()), nil
+}
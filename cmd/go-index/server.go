@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/mauhlik/go-index/config"
+	"github.com/mauhlik/go-index/internal/go-index/tlsconfig"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPort              = "8080"
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultIdleTimeout       = 2 * time.Minute
+	defaultShutdownTimeout   = 30 * time.Second
+)
+
+func newHTTPServer(cfg *config.Config, handler http.Handler, logger *logrus.Logger) (*http.Server, error) {
+	conf := cfg.Server
+
+	addr := conf.Listen
+	if addr == "" {
+		port := cfg.Port
+		if port == "" {
+			port = defaultPort
+		}
+
+		addr = ":" + port
+	}
+
+	readHeaderTimeout := conf.ReadHeaderTimeout.Duration()
+	if readHeaderTimeout == 0 {
+		readHeaderTimeout = defaultReadHeaderTimeout
+	}
+
+	idleTimeout := conf.IdleTimeout.Duration()
+	if idleTimeout == 0 {
+		idleTimeout = defaultIdleTimeout
+	}
+
+	maxHeaderBytes := conf.MaxHeaderBytes
+	if maxHeaderBytes == 0 {
+		maxHeaderBytes = http.DefaultMaxHeaderBytes
+	}
+
+	var tlsConfig *tls.Config
+
+	if conf.TLS.CertFile != "" || conf.TLS.KeyFile != "" {
+		var err error
+
+		tlsConfig, err = tlsconfig.New(tlsconfig.Options{
+			CertFile:       conf.TLS.CertFile,
+			KeyFile:        conf.TLS.KeyFile,
+			ClientCAFile:   conf.TLS.ClientCAFile,
+			ClientAuth:     conf.TLS.ClientAuth,
+			MinVersion:     conf.TLS.MinVersion,
+			ReloadInterval: conf.TLS.ReloadInterval.Duration(),
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup TLS: %w", err)
+		}
+	}
+
+	return &http.Server{ //nolint:exhaustruct
+		TLSConfig:         tlsConfig,
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       conf.ReadTimeout.Duration(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      conf.WriteTimeout.Duration(),
+		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    maxHeaderBytes,
+	}, nil
+}
+
+// serve runs the server until ctx is done, then stops accepting connections and gives in-flight requests
+// up to grace to finish before closing them.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, grace time.Duration,
+	logger *logrus.Logger) error {
+	if grace <= 0 {
+		grace = defaultShutdownTimeout
+	}
+
+	served := make(chan error, 1)
+
+	go func() {
+		if server.TLSConfig != nil {
+			served <- server.ServeTLS(listener, "", "")
+		} else {
+			served <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("server stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Warnf("Shutting down, draining in-flight requests for up to %s", grace)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+
+		return fmt.Errorf("failed to drain requests: %w", err)
+	}
+
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+
+	return nil
+}
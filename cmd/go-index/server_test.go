@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mauhlik/go-index/config"
+	"github.com/sirupsen/logrus"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+	t.Parallel()
+
+	server, err := newHTTPServer(&config.Config{Port: "9090"}, http.NotFoundHandler(), logrus.New())
+	if err != nil {
+		t.Fatalf("newHTTPServer returned an error: %v", err)
+	}
+
+	if server.Addr != ":9090" || server.ReadHeaderTimeout != defaultReadHeaderTimeout ||
+		server.IdleTimeout != defaultIdleTimeout || server.MaxHeaderBytes != http.DefaultMaxHeaderBytes {
+		t.Errorf("newHTTPServer returned %+v; want defaults on :9090", server)
+	}
+
+	server, err = newHTTPServer(&config.Config{
+		Port: "9090",
+		Server: config.ServerConfig{
+			Listen:         "127.0.0.1:7070",
+			WriteTimeout:   config.Duration(time.Minute),
+			MaxHeaderBytes: 4096,
+		},
+	}, http.NotFoundHandler(), logrus.New())
+	if err != nil {
+		t.Fatalf("newHTTPServer returned an error: %v", err)
+	}
+
+	if server.Addr != "127.0.0.1:7070" || server.WriteTimeout != time.Minute || server.MaxHeaderBytes != 4096 {
+		t.Errorf("newHTTPServer returned %+v; want the configured listen address and limits", server)
+	}
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(writer, "done")
+	})
+
+	server, err := newHTTPServer(&config.Config{}, handler, logrus.New())
+	if err != nil {
+		t.Fatalf("newHTTPServer returned an error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+
+	go func() {
+		served <- serve(ctx, server, listener, time.Second, logrus.New())
+	}()
+
+	response := make(chan string, 1)
+
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			response <- err.Error()
+
+			return
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	if body := <-response; body != "done" {
+		t.Errorf("In-flight request returned %q; want it to complete", body)
+	}
+
+	if err := <-served; err != nil {
+		t.Errorf("serve returned %v; want a clean shutdown", err)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mauhlik/go-index/config"
+)
+
+// runValidate checks the file given as argument, or -config, and returns 0 when it is valid and 1 when
+// it is not, listing every problem. A file that cannot be decoded is reported alone, before validation.
+// With -schema it prints the JSON Schema of the configuration instead.
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	flags, opts := newFlagSet("validate", stderr)
+	printSchema := flags.Bool("schema", false, "print the JSON Schema of the configuration and exit")
+
+	if code, ok := parseFlags(flags, opts, args, -1); !ok {
+		return code
+	}
+
+	if *printSchema {
+		if _, err := stdout.Write(config.Schema); err != nil {
+			fmt.Fprintf(stderr, "Failed to write schema: %v\n", err)
+
+			return exitFailure
+		}
+
+		return exitOK
+	}
+
+	filename := opts.configFile
+
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		filename = flags.Arg(0)
+	default:
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	if _, err := config.LoadConfig(filename); err != nil {
+		var validationErr *config.ValidationError
+		if !errors.As(err, &validationErr) {
+			// Files that cannot be read or decoded are not validated, so fixing this may reveal more problems.
+			fmt.Fprintf(stderr, "%s: %v\n%s: not validated; fix the error above first\n", filename, err, filename)
+
+			return exitFailure
+		}
+
+		for _, fieldError := range validationErr.Errors {
+			fmt.Fprintf(stderr, "%s: %v\n", filename, fieldError)
+		}
+
+		return exitFailure
+	}
+
+	fmt.Fprintf(stdout, "%s is valid\n", filename)
+
+	return exitOK
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunValidate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yml")
+	invalid := filepath.Join(dir, "invalid.yml")
+	malformed := filepath.Join(dir, "malformed.yml")
+
+	for filename, content := range map[string]string{
+		valid: "repositories:\n  - name: files\n    provider: local\n" +
+			"providers:\n  local:\n    type: local\n    path: /srv\n",
+		invalid: "repositories:\n  - name: files\n    provider: s3\n  - name: files\n    provider: local\n" +
+			"providers:\n  local:\n    type: local\n    bucket: artifacts\n",
+		malformed: "repositories:\n  - name: files\n    publish: sometimes\n",
+	} {
+		if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", filename, err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	if code := runValidate([]string{valid}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "is valid") {
+		t.Errorf("runValidate returned %d with %q; want 0", code, stderr.String())
+	}
+
+	stderr.Reset()
+
+	if code := runValidate([]string{invalid}, &stdout, &stderr); code != 1 {
+		t.Errorf("runValidate returned %d for an invalid file; want 1", code)
+	}
+
+	for _, path := range []string{"repositories[0].provider", "repositories[1].name", "providers.local.bucket",
+		"providers.local.path"} {
+		if !strings.Contains(stderr.String(), path) {
+			t.Errorf("runValidate output %q does not report %s", stderr.String(), path)
+		}
+	}
+
+	stderr.Reset()
+
+	code := runValidate([]string{malformed}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "not validated") {
+		t.Errorf("runValidate returned %d with %q for a malformed file; want 1 and a note that it was not validated",
+			code, stderr.String())
+	}
+
+	if code := runValidate([]string{valid, invalid}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("runValidate returned %d for two files; want %d", code, exitUsage)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func TestRunValidateSchemaWriteError(t *testing.T) {
+	t.Parallel()
+
+	var stderr bytes.Buffer
+
+	if code := runValidate([]string{"-schema"}, failingWriter{}, &stderr); code != exitFailure ||
+		!strings.Contains(stderr.String(), "Failed to write schema") {
+		t.Errorf("runValidate returned %d with %q; want %d and the write error", code, stderr.String(), exitFailure)
+	}
+}
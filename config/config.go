@@ -1,26 +1,154 @@
 package config
 
+const (
+	RepositoryModeGeneric = "generic"
+	RepositoryModeGoProxy = "goproxy"
+
+	// ReservedRepositoryName cannot name a repository since /api/search is the search endpoint.
+	ReservedRepositoryName = "search"
+)
+
 type LocalProviderConfig struct {
 	Type string `json:"type" yaml:"type"`
 	Path string `json:"path" yaml:"path"` // Path is required for local provider
 }
 
+// S3ProviderConfig uses the default AWS credential chain (environment, shared files, IRSA, instance roles)
+// unless AccessKey and SecretKey are set.
 type S3ProviderConfig struct {
-	Type      string `json:"type" yaml:"type"`
-	Bucket    string `json:"bucket" yaml:"bucket"`       // Bucket is required for S3 provider
-	Endpoint  string `json:"endpoint" yaml:"endpoint"`   // Endpoint is required for S3 provider
-	AccessKey string `json:"accessKey" yaml:"accessKey"` // AccessKey is required for S3 provider
-	SecretKey string `json:"secretKey" yaml:"secretKey"` // SecretKey is required for S3 provider
-	Region    string `json:"region" yaml:"region"`       // Region is required for S3 provider
+	Type     string `json:"type" yaml:"type"`
+	Bucket   string `json:"bucket" yaml:"bucket"`     // Bucket is required for S3 provider
+	Endpoint string `json:"endpoint" yaml:"endpoint"` // Endpoint overrides the AWS endpoint, e.g. for MinIO
+	Region   string `json:"region" yaml:"region"`
+	// AccessKey and SecretKey are static credentials
+	AccessKey Secret `json:"accessKey" yaml:"accessKey"`
+	SecretKey Secret `json:"secretKey" yaml:"secretKey"`
+	Profile   string `json:"profile" yaml:"profile"` // Profile selects a profile of the shared AWS config files
+	// AssumeRoleARN is assumed through STS with the resolved credentials
+	AssumeRoleARN string `json:"assumeRoleArn" yaml:"assumeRoleArn"`
+	// UsePathStyle addresses objects as endpoint/bucket/key, as MinIO requires
+	UsePathStyle bool   `json:"usePathStyle" yaml:"usePathStyle"`
+	CABundle     string `json:"caBundle" yaml:"caBundle"` // CABundle is a PEM file of additional trusted CAs
+	Prefix       string `json:"prefix" yaml:"prefix"`     // Prefix is a folder inside the bucket
+}
+
+type DownloadConfig struct {
+	// Redirect sends clients to a presigned URL when the provider supports it (S3)
+	Redirect      bool     `json:"redirect" yaml:"redirect"`
+	PresignExpiry Duration `json:"presignExpiry" yaml:"presignExpiry"` // PresignExpiry defaults to 5m
+}
+
+type AccessConfig struct {
+	// Anonymous is the role of unauthenticated callers: read (default), none, publish or admin
+	Anonymous string `json:"anonymous" yaml:"anonymous"`
+	// Roles maps user names, or * for any authenticated user, to read, publish or admin; admins may delete versions
+	Roles map[string]string `json:"roles" yaml:"roles"`
+}
+
+type CacheConfig struct {
+	TTL Duration `json:"ttl" yaml:"ttl"` // TTL enables caching of listings when set
+	// StaleTTL is how long after TTL a listing is still served while it is refreshed in the background
+	StaleTTL Duration `json:"staleTtl" yaml:"staleTtl"`
+	// MaxEntries bounds the cached listings; the least recently used are evicted first. Defaults to 10000
+	MaxEntries int `json:"maxEntries" yaml:"maxEntries"`
 }
 
 type RepositoryConfig struct {
 	Name     string `json:"name" yaml:"name"`
 	Provider string `json:"provider" yaml:"provider"`
+	Mode     string `json:"mode" yaml:"mode"` // Mode is generic (default) or goproxy
+	// InvalidVersions is skip (default), strict or coerce
+	InvalidVersions string `json:"invalidVersions" yaml:"invalidVersions"`
+	// Scheme is semver (default), calver, pep440, maven or debian
+	Scheme string `json:"scheme" yaml:"scheme"`
+	// Layout is a path template such as releases/{module}/{version}/{artifact}_{version}_{os}_{arch}.tar.gz
+	Layout string `json:"layout" yaml:"layout"`
+	// LayoutRegex is a regular expression with a named version group, exclusive with Layout
+	LayoutRegex string         `json:"layoutRegex" yaml:"layoutRegex"`
+	Download    DownloadConfig `json:"download" yaml:"download"`
+	// Publish enables PUT/POST uploads to /:module/:artifact/versions/:version, and DELETE of it by admins
+	Publish bool         `json:"publish" yaml:"publish"`
+	Access  AccessConfig `json:"access" yaml:"access"`
+	Cache   CacheConfig  `json:"cache" yaml:"cache"`
+	// Timeout bounds the provider calls of each read request, answered with 504 when exceeded; 0 disables it
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+}
+
+// UserConfig credentials are hashes, but are kept secret anyway: a leaked hash allows offline guessing.
+type UserConfig struct {
+	Name         string   `json:"name" yaml:"name"`
+	PasswordHash Secret   `json:"passwordHash" yaml:"passwordHash"` // PasswordHash is bcrypt, for HTTP basic
+	TokenHashes  []Secret `json:"tokenHashes" yaml:"tokenHashes"`   // TokenHashes are hex SHA-256 of API tokens
+}
+
+type OIDCRuleConfig struct {
+	// Claims maps claim names to path.Match patterns, all of which must match
+	Claims       map[string]string `json:"claims" yaml:"claims"`
+	Repositories map[string]string `json:"repositories" yaml:"repositories"` // Repositories maps names to roles
+}
+
+type OIDCConfig struct {
+	Issuer   string `json:"issuer" yaml:"issuer"`     // Issuer enables OIDC bearer authentication
+	Audience string `json:"audience" yaml:"audience"` // Audience is required and must be in the aud claim
+	// JWKSFile and JWKSURL are mutually exclusive sources of the signing keys
+	JWKSFile      string           `json:"jwksFile" yaml:"jwksFile"`
+	JWKSURL       string           `json:"jwksUrl" yaml:"jwksUrl"`
+	JWKSRefresh   Duration         `json:"jwksRefresh" yaml:"jwksRefresh"`     // JWKSRefresh defaults to 1h
+	UsernameClaim string           `json:"usernameClaim" yaml:"usernameClaim"` // UsernameClaim defaults to sub
+	Rules         []OIDCRuleConfig `json:"rules" yaml:"rules"`
+}
+
+type AuthConfig struct {
+	Users []UserConfig `json:"users" yaml:"users"`
+	OIDC  OIDCConfig   `json:"oidc" yaml:"oidc"`
+}
+
+type HealthConfig struct {
+	Timeout Duration `json:"timeout" yaml:"timeout"` // Timeout bounds each readiness check, default 5s
+}
+
+type TLSConfig struct {
+	CertFile string `json:"certFile" yaml:"certFile"` // CertFile and KeyFile enable HTTPS
+	KeyFile  string `json:"keyFile" yaml:"keyFile"`
+	// ClientCAFile enables mutual TLS; the client certificate common name is used as the user name
+	ClientCAFile string `json:"clientCaFile" yaml:"clientCaFile"`
+	ClientAuth   string `json:"clientAuth" yaml:"clientAuth"` // ClientAuth is require (default) or optional
+	MinVersion   string `json:"minVersion" yaml:"minVersion"` // MinVersion is 1.2 (default) or 1.3
+	// ReloadInterval is how often the files are checked for changes, default 10s
+	ReloadInterval Duration `json:"reloadInterval" yaml:"reloadInterval"`
+}
+
+// ServerConfig timeouts default to none except ReadHeaderTimeout (10s) and IdleTimeout (2m); reads and
+// writes stay unbounded so large uploads and downloads are not cut off.
+type ServerConfig struct {
+	Listen            string   `json:"listen" yaml:"listen"` // Listen is host:port and takes precedence over Port
+	ReadTimeout       Duration `json:"readTimeout" yaml:"readTimeout"`
+	ReadHeaderTimeout Duration `json:"readHeaderTimeout" yaml:"readHeaderTimeout"`
+	WriteTimeout      Duration `json:"writeTimeout" yaml:"writeTimeout"`
+	IdleTimeout       Duration `json:"idleTimeout" yaml:"idleTimeout"`
+	MaxHeaderBytes    int      `json:"maxHeaderBytes" yaml:"maxHeaderBytes"` // MaxHeaderBytes defaults to 1 MiB
+	// ShutdownTimeout is how long in-flight requests may drain after SIGTERM, default 30s
+	ShutdownTimeout Duration  `json:"shutdownTimeout" yaml:"shutdownTimeout"`
+	TLS             TLSConfig `json:"tls" yaml:"tls"`
+}
+
+type ReloadConfig struct {
+	// Interval is how often the config file is checked for changes, default 5s; SIGHUP reloads immediately
+	Interval Duration `json:"interval" yaml:"interval"`
+}
+
+type SearchConfig struct {
+	// RefreshInterval is how often the index of module and artifact names is rebuilt, default 10m
+	RefreshInterval Duration `json:"refreshInterval" yaml:"refreshInterval"`
 }
 
 type Config struct {
 	Port         string                 `json:"port" yaml:"port"`
+	Server       ServerConfig           `json:"server" yaml:"server"`
 	Repositories []RepositoryConfig     `json:"repositories" yaml:"repositories"`
 	Providers    map[string]interface{} `json:"providers" yaml:"providers"`
+	Auth         AuthConfig             `json:"auth" yaml:"auth"`
+	Health       HealthConfig           `json:"health" yaml:"health"`
+	Reload       ReloadConfig           `json:"reload" yaml:"reload"`
+	Search       SearchConfig           `json:"search" yaml:"search"`
 }
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change is one difference between two configurations; Path is a section such as auth, or a named
+// entry such as repositories.files.
+type Change struct {
+	Path string
+	Kind string
+}
+
+func (c Change) String() string {
+	return c.Path + " " + c.Kind
+}
+
+// RestartRequired reports whether the change only takes effect once the listener is recreated.
+func (c Change) RestartRequired() bool {
+	return c.Path == "port" || c.Path == "server"
+}
+
+// Diff lists the repositories, providers and sections that differ between old and updated, in that order.
+func Diff(old, updated *Config) []Change {
+	var changes []Change
+
+	oldRepositories := map[string]RepositoryConfig{}
+	for _, repo := range old.Repositories {
+		oldRepositories[repo.Name] = repo
+	}
+
+	updatedRepositories := map[string]RepositoryConfig{}
+	for _, repo := range updated.Repositories {
+		updatedRepositories[repo.Name] = repo
+	}
+
+	changes = append(changes, diffEntries("repositories.", oldRepositories, updatedRepositories)...)
+	changes = append(changes, diffEntries("providers.", old.Providers, updated.Providers)...)
+
+	for _, section := range []struct {
+		path         string
+		old, updated interface{}
+	}{
+		{"port", old.Port, updated.Port},
+		{"server", old.Server, updated.Server},
+		{"auth", old.Auth, updated.Auth},
+		{"health", old.Health, updated.Health},
+		{"reload", old.Reload, updated.Reload},
+		{"search", old.Search, updated.Search},
+	} {
+		if !reflect.DeepEqual(section.old, section.updated) {
+			changes = append(changes, Change{Path: section.path, Kind: ChangeChanged})
+		}
+	}
+
+	return changes
+}
+
+func diffEntries[V any](prefix string, old, updated map[string]V) []Change {
+	names := map[string]bool{}
+	for name := range old {
+		names[name] = true
+	}
+
+	for name := range updated {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+
+	sort.Strings(sorted)
+
+	var changes []Change
+
+	for _, name := range sorted {
+		oldValue, inOld := old[name]
+		updatedValue, inUpdated := updated[name]
+
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Path: prefix + name, Kind: ChangeAdded})
+		case !inUpdated:
+			changes = append(changes, Change{Path: prefix + name, Kind: ChangeRemoved})
+		case !reflect.DeepEqual(oldValue, updatedValue):
+			changes = append(changes, Change{Path: prefix + name, Kind: ChangeChanged})
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mauhlik/go-index/config"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	old := &config.Config{
+		Port: "8080",
+		Repositories: []config.RepositoryConfig{
+			{Name: "files", Provider: "local"},
+			{Name: "gomods", Provider: "local", Mode: config.RepositoryModeGoProxy},
+		},
+		Providers: map[string]interface{}{"local": config.LocalProviderConfig{Type: "local", Path: "/srv"}},
+	}
+	updated := &config.Config{
+		Port: "9090",
+		Repositories: []config.RepositoryConfig{
+			{Name: "files", Provider: "local", Publish: true},
+			{Name: "charts", Provider: "local"},
+		},
+		Providers: map[string]interface{}{"local": config.LocalProviderConfig{Type: "local", Path: "/srv"}},
+	}
+
+	want := []config.Change{
+		{Path: "repositories.charts", Kind: config.ChangeAdded},
+		{Path: "repositories.files", Kind: config.ChangeChanged},
+		{Path: "repositories.gomods", Kind: config.ChangeRemoved},
+		{Path: "port", Kind: config.ChangeChanged},
+	}
+
+	changes := config.Diff(old, updated)
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("Diff returned %v; want %v", changes, want)
+	}
+
+	if !changes[3].RestartRequired() || changes[0].RestartRequired() {
+		t.Error("Only the port change should require a restart")
+	}
+
+	if changes := config.Diff(old, old); len(changes) != 0 {
+		t.Errorf("Diff of identical configs returned %v", changes)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidDuration = errors.New("invalid duration")
+
+// Duration accepts Go duration strings such as "30s" or "5m", or a number of seconds.
+type Duration time.Duration
+
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(time.Duration(d).String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal duration: %w", err)
+	}
+
+	return data, nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDuration, err)
+	}
+
+	return d.set(value)
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDuration, err)
+	}
+
+	return d.set(value)
+}
+
+func (d *Duration) set(value interface{}) error {
+	switch typed := value.(type) {
+	case string:
+		duration, err := time.ParseDuration(typed)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidDuration, err)
+		}
+
+		*d = Duration(duration)
+	case int:
+		*d = Duration(time.Duration(typed) * time.Second)
+	case float64:
+		*d = Duration(time.Duration(typed * float64(time.Second)))
+	default:
+		return fmt.Errorf("%w: %v", ErrInvalidDuration, value)
+	}
+
+	return nil
+}
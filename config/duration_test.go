@@ -0,0 +1,42 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mauhlik/go-index/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDurationUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	var fromJSON struct {
+		Expiry config.Duration `json:"expiry"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"expiry": "90s"}`), &fromJSON); err != nil {
+		t.Fatalf("Failed to unmarshal JSON duration: %v", err)
+	}
+
+	if fromJSON.Expiry.Duration() != 90*time.Second {
+		t.Errorf("JSON duration is %v; want %v", fromJSON.Expiry.Duration(), 90*time.Second)
+	}
+
+	var fromYAML struct {
+		Expiry config.Duration `yaml:"expiry"`
+	}
+
+	if err := yaml.Unmarshal([]byte("expiry: 30"), &fromYAML); err != nil {
+		t.Fatalf("Failed to unmarshal YAML duration: %v", err)
+	}
+
+	if fromYAML.Expiry.Duration() != 30*time.Second {
+		t.Errorf("YAML duration is %v; want %v", fromYAML.Expiry.Duration(), 30*time.Second)
+	}
+
+	if err := yaml.Unmarshal([]byte("expiry: soon"), &fromYAML); err == nil {
+		t.Error("Expected an error for an invalid duration")
+	}
+}
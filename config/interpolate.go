@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const filePrefix = "file:"
+
+var (
+	ErrMissingVariable = errors.New("environment variable is not set")
+	ErrSecretFile      = errors.New("failed to read secret file")
+)
+
+// variablePattern matches ${NAME}; a doubled $$ escapes it. Bare $NAME is left alone since bcrypt
+// hashes contain dollar signs.
+var variablePattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolate expands ${NAME} references to environment variables in every string of config, then
+// replaces values of the form file:<path> with the contents of the file, without the trailing newline.
+// Values that cannot be expanded are reported at their path and left as written.
+func (v *validator) interpolate(config *Config) {
+	v.interpolateValue(reflect.ValueOf(config).Elem(), "")
+}
+
+func (v *validator) interpolateValue(value reflect.Value, path string) {
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.String:
+		expanded, err := expand(value.String())
+		if err != nil {
+			v.fail(path, err)
+
+			return
+		}
+
+		value.SetString(expanded)
+	case reflect.Struct:
+		for index := range value.NumField() {
+			field := value.Type().Field(index)
+			if field.IsExported() {
+				v.interpolateValue(value.Field(index), joinPath(path, fieldName(field)))
+			}
+		}
+	case reflect.Slice:
+		for index := range value.Len() {
+			v.interpolateValue(value.Index(index), path+"["+strconv.Itoa(index)+"]")
+		}
+	case reflect.Map:
+		// Map values are not addressable, so each one is expanded in a copy and stored back. Keys are
+		// sorted so problems are reported in a stable order.
+		keys := value.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)) })
+
+		for _, key := range keys {
+			entry := reflect.New(value.MapIndex(key).Type()).Elem()
+			entry.Set(value.MapIndex(key))
+			v.interpolateValue(entry, joinPath(path, fmt.Sprint(key)))
+			value.SetMapIndex(key, entry)
+		}
+	case reflect.Interface:
+		if value.IsNil() {
+			return
+		}
+
+		entry := reflect.New(value.Elem().Type()).Elem()
+		entry.Set(value.Elem())
+		v.interpolateValue(entry, path)
+		value.Set(entry)
+	}
+}
+
+func expand(value string) (string, error) {
+	var missing []string
+
+	expanded := variablePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		if strings.HasPrefix(reference, "$$") {
+			return reference[1:]
+		}
+
+		name := reference[2 : len(reference)-1]
+
+		variable, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+
+		return variable
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingVariable, strings.Join(missing, ", "))
+	}
+
+	filename, ok := strings.CutPrefix(expanded, filePrefix)
+	if !ok {
+		return expanded, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSecretFile, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+
+	return field.Name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
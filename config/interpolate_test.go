@@ -0,0 +1,120 @@
+package config_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mauhlik/go-index/config"
+)
+
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+
+	filename := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	return filename
+}
+
+func TestLoadConfigInterpolation(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret-key")
+
+	if err := os.WriteFile(secretFile, []byte("MzTDkSsvcJgHAoSU2D4Z7\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+
+	t.Setenv("GO_INDEX_BUCKET", "artifacts")
+	t.Setenv("GO_INDEX_SECRETS", dir)
+
+	cfg, err := config.LoadConfig(writeConfig(t, dir, `
+auth:
+  users:
+    - name: ci
+      passwordHash: $2a$10$abcdefghijklmnopqrstuv
+repositories:
+  - name: files
+    provider: s3
+providers:
+  s3:
+    type: s3
+    bucket: ${GO_INDEX_BUCKET}
+    endpoint: https://${GO_INDEX_BUCKET}.s3.example.com
+    accessKey: 9wnheHR37PwXdE8YF56U
+    secretKey: file:${GO_INDEX_SECRETS}/secret-key
+    prefix: mirror-$${literal}
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig returned an error: %v", err)
+	}
+
+	s3Config, _ := cfg.Providers["s3"].(config.S3ProviderConfig)
+	if s3Config.Bucket != "artifacts" || s3Config.Endpoint != "https://artifacts.s3.example.com" {
+		t.Errorf("Variables were not expanded: %+v", s3Config)
+	}
+
+	if s3Config.SecretKey.Value() != "MzTDkSsvcJgHAoSU2D4Z7" {
+		t.Errorf("Secret key is %q; want the file contents", s3Config.SecretKey.Value())
+	}
+
+	if hash := cfg.Auth.Users[0].PasswordHash; hash != "$2a$10$abcdefghijklmnopqrstuv" {
+		t.Errorf("Password hash is %q; want it unchanged", hash)
+	}
+
+	if s3Config.Prefix != "mirror-${literal}" {
+		t.Errorf("Prefix is %q; want the escaped reference kept", s3Config.Prefix)
+	}
+}
+
+func TestLoadConfigInterpolationErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name    string
+		content string
+		want    error
+		path    string
+	}{
+		{"missing variable", "repositories:\n  - name: ${GO_INDEX_UNSET_VARIABLE}\n",
+			config.ErrMissingVariable, "repositories[0].name"},
+		{"missing file", "providers:\n  s3:\n    type: s3\n    secretKey: file:" + filepath.Join(dir, "missing") + "\n",
+			config.ErrSecretFile, "providers.s3.secretKey"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := config.LoadConfig(writeConfig(t, t.TempDir(), testCase.content))
+			if !errors.Is(err, testCase.want) || !strings.Contains(err.Error(), testCase.path) {
+				t.Errorf("LoadConfig returned %v; want %v at %s", err, testCase.want, testCase.path)
+			}
+		})
+	}
+}
+
+func TestSecretRedaction(t *testing.T) {
+	t.Parallel()
+
+	provider := config.S3ProviderConfig{Type: "s3", Bucket: "artifacts", SecretKey: "MzTDkSsvcJgHAoSU2D4Z7"}
+
+	data, err := json.Marshal(provider)
+	if err != nil {
+		t.Fatalf("Failed to marshal provider: %v", err)
+	}
+
+	for _, output := range []string{fmt.Sprintf("%v %+v %#v", provider, provider, provider), string(data)} {
+		if strings.Contains(output, "MzTDkSsvcJgHAoSU2D4Z7") || !strings.Contains(output, "[REDACTED]") {
+			t.Errorf("Output leaks the secret: %s", output)
+		}
+	}
+}
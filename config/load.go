@@ -4,8 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
@@ -15,70 +20,155 @@ var (
 	ErrInvalidProviderFormat    = errors.New("invalid provider configuration format")
 	ErrProviderTypeRequired     = errors.New("provider type is required")
 	ErrUnsupportedProviderType  = errors.New("unsupported provider type")
+	ErrUnknownKey               = errors.New("unknown key")
 )
 
+// LoadConfig reads a JSON or YAML file. Any string may reference environment variables as ${NAME} or
+// be given as file:<path> to read it from a file, such as a mounted secret. Unknown keys, values that
+// cannot be expanded and the problems found by Validate are returned together as a *ValidationError;
+// a file that cannot be parsed, or holds a value of the wrong type, fails before anything is validated.
 func LoadConfig(filename string) (*Config, error) {
-	file, err := os.Open(filename)
+	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open config file: %w", err)
 	}
-	defer file.Close()
 
 	var config Config
 
 	ext := filepath.Ext(filename)
+	validator := newValidator()
 
-	if err := decodeConfig(file, ext, &config); err != nil {
+	if err := validator.decodeConfig(data, ext, &config); err != nil {
 		return nil, err
 	}
 
-	if err := parseProviderConfigs(&config, ext); err != nil {
+	validator.parseProviderConfigs(&config, ext)
+	validator.interpolate(&config)
+	validator.validate(&config)
+
+	if err := validator.result(); err != nil {
 		return nil, err
 	}
 
 	return &config, nil
 }
 
-func decodeConfig(file *os.File, ext string, config *Config) error {
+// decodeConfig decodes data leniently and reports the keys config has no field for, so one typo does
+// not hide the rest of the problems.
+func (v *validator) decodeConfig(data []byte, ext string, config *Config) error {
+	var document interface{}
+
 	switch ext {
 	case ".json":
-		decoder := json.NewDecoder(file)
-		if err := decoder.Decode(config); err != nil {
+		if err := json.Unmarshal(data, config); err != nil {
+			return fmt.Errorf("failed to decode JSON config file: %w", err)
+		}
+
+		if err := json.Unmarshal(data, &document); err != nil {
 			return fmt.Errorf("failed to decode JSON config file: %w", err)
 		}
 	case ".yaml", ".yml":
-		decoder := yaml.NewDecoder(file)
-		if err := decoder.Decode(config); err != nil {
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return fmt.Errorf("failed to decode YAML config file: %w", err)
+		}
+
+		if err := yaml.Unmarshal(data, &document); err != nil {
 			return fmt.Errorf("failed to decode YAML config file: %w", err)
 		}
 	default:
 		return fmt.Errorf("%w: %s", ErrUnsupportedFileExtension, ext)
 	}
 
+	v.unknownKeys(document, reflect.TypeOf(config).Elem(), "", ext == ".json")
+
 	return nil
 }
 
-func parseProviderConfigs(config *Config, ext string) error {
-	for key, value := range config.Providers {
-		providerMap, err := getProviderMap(value)
+// unknownKeys reports the keys of document, as decoded into interface{}, that no field of target takes.
+// JSON keys match case-insensitively, as they do for encoding/json.
+func (v *validator) unknownKeys(document interface{}, target reflect.Type, path string, foldCase bool) {
+	switch target.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		entries, ok := documentMap(document)
+		if !ok {
+			return
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(entries)) {
+			field, ok := findField(target, key, foldCase)
+			if !ok {
+				v.fail(joinPath(path, key), ErrUnknownKey)
+
+				continue
+			}
+
+			v.unknownKeys(entries[key], field.Type, joinPath(path, fieldName(field)), foldCase)
+		}
+	case reflect.Map:
+		entries, _ := documentMap(document)
+		for _, key := range slices.Sorted(maps.Keys(entries)) {
+			v.unknownKeys(entries[key], target.Elem(), joinPath(path, key), foldCase)
+		}
+	case reflect.Slice:
+		items, _ := document.([]interface{})
+		for index, item := range items {
+			v.unknownKeys(item, target.Elem(), path+"["+strconv.Itoa(index)+"]", foldCase)
+		}
+	}
+}
+
+func documentMap(document interface{}) (map[string]interface{}, bool) {
+	switch entries := document.(type) {
+	case map[string]interface{}:
+		return entries, true
+	case map[interface{}]interface{}:
+		return convertMap(entries), true
+	default:
+		return nil, false
+	}
+}
+
+func findField(target reflect.Type, key string, foldCase bool) (reflect.StructField, bool) {
+	for index := range target.NumField() {
+		field := target.Field(index)
+		if name := fieldName(field); field.IsExported() && (name == key || foldCase && strings.EqualFold(name, key)) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// parseProviderConfigs replaces each provider map with its typed configuration. Providers that cannot be
+// parsed are reported and left as they are.
+func (v *validator) parseProviderConfigs(config *Config, ext string) {
+	for _, key := range slices.Sorted(maps.Keys(config.Providers)) {
+		path := "providers." + key
+
+		providerMap, err := getProviderMap(config.Providers[key])
 		if err != nil {
-			return fmt.Errorf("%w: %s", err, key)
+			v.fail(path, err)
+
+			continue
 		}
 
 		providerType, ok := providerMap["type"].(string)
 		if !ok {
-			return fmt.Errorf("%w: %s", ErrProviderTypeRequired, key)
+			v.fail(path, ErrProviderTypeRequired)
+
+			continue
 		}
 
 		providerConfig, err := getProviderConfig(providerType, providerMap, ext)
 		if err != nil {
-			return err
+			v.fail(path, err)
+
+			continue
 		}
 
+		v.unknownKeys(providerMap, reflect.TypeOf(providerConfig), path, ext == ".json")
 		config.Providers[key] = providerConfig
 	}
-
-	return nil
 }
 
 func getProviderMap(value interface{}) (map[string]interface{}, error) {
@@ -0,0 +1,9 @@
+package config
+
+import _ "embed"
+
+// Schema is the JSON Schema of the configuration file, for editors and CI checks. Validate applies the
+// same rules plus the ones a schema cannot express, such as references between repositories and providers.
+//
+//go:embed schema.json
+var Schema []byte
@@ -0,0 +1,332 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+)
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+var (
+	repositoryModes  = []string{"", RepositoryModeGeneric, RepositoryModeGoProxy}
+	invalidVersions  = []string{"", "skip", "strict", "coerce"}
+	versionSchemes   = []string{"", "semver", "calver", "pep440", "maven", "debian"}
+	roleNames        = []string{"none", "read", "publish", "admin"}
+	clientAuthModes  = []string{"", "require", "optional"}
+	tlsVersions      = []string{"", "1.2", "1.3"}
+	repositoryNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// FieldError is one problem found by Validate, at a path such as repositories[0].provider. Err is the
+// underlying error of problems found while loading, such as ErrUnknownKey or ErrMissingVariable.
+type FieldError struct {
+	Path    string
+	Message string
+	Err     error
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors))
+	for _, fieldError := range e.Errors {
+		lines = append(lines, fieldError.Error())
+	}
+
+	return fmt.Sprintf("%s: %s", ErrInvalidConfig, strings.Join(lines, "; "))
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := []error{ErrInvalidConfig}
+
+	for _, fieldError := range e.Errors {
+		if fieldError.Err != nil {
+			errs = append(errs, fieldError)
+		}
+	}
+
+	return errs
+}
+
+// validator collects the problems of a configuration. Paths that failed to load are not validated
+// again, so a missing variable is not also reported as an empty value.
+type validator struct {
+	errors []FieldError
+	failed map[string]bool
+}
+
+func newValidator() *validator {
+	return &validator{errors: nil, failed: map[string]bool{}}
+}
+
+func (v *validator) add(path, format string, args ...interface{}) {
+	if v.failed[path] {
+		return
+	}
+
+	v.errors = append(v.errors, FieldError{Path: path, Message: fmt.Sprintf(format, args...), Err: nil})
+}
+
+// fail records a problem found while loading the value at path.
+func (v *validator) fail(path string, err error) {
+	v.failed[path] = true
+	v.errors = append(v.errors, FieldError{Path: path, Message: err.Error(), Err: err})
+}
+
+func (v *validator) result() error {
+	if len(v.errors) > 0 {
+		return &ValidationError{Errors: v.errors}
+	}
+
+	return nil
+}
+
+func (v *validator) oneOf(path, value string, allowed []string) {
+	if !slices.Contains(allowed, value) {
+		v.add(path, "must be one of %s, got %q", strings.Join(slices.DeleteFunc(slices.Clone(allowed),
+			func(name string) bool { return name == "" }), ", "), value)
+	}
+}
+
+func (v *validator) nonNegative(path string, duration Duration) {
+	if duration < 0 {
+		v.add(path, "must not be negative")
+	}
+}
+
+// Validate checks the configuration as a whole and returns a *ValidationError listing every problem,
+// or nil when the configuration is usable.
+func (c *Config) Validate() error {
+	validator := newValidator()
+	validator.validate(c)
+
+	return validator.result()
+}
+
+func (v *validator) validate(config *Config) {
+	v.validateServer(config)
+	v.validateRepositories(config)
+	v.validateProviders(config.Providers)
+	v.validateAuth(config)
+	v.nonNegative("health.timeout", config.Health.Timeout)
+	v.nonNegative("reload.interval", config.Reload.Interval)
+	v.nonNegative("search.refreshInterval", config.Search.RefreshInterval)
+}
+
+func (v *validator) validateServer(config *Config) {
+	if config.Port != "" {
+		if port, err := strconv.Atoi(config.Port); err != nil || port < 1 || port > 65535 {
+			v.add("port", "must be a port number, got %q", config.Port)
+		}
+	}
+
+	server := config.Server
+
+	v.nonNegative("server.readTimeout", server.ReadTimeout)
+	v.nonNegative("server.readHeaderTimeout", server.ReadHeaderTimeout)
+	v.nonNegative("server.writeTimeout", server.WriteTimeout)
+	v.nonNegative("server.idleTimeout", server.IdleTimeout)
+	v.nonNegative("server.shutdownTimeout", server.ShutdownTimeout)
+	v.nonNegative("server.tls.reloadInterval", server.TLS.ReloadInterval)
+
+	if server.MaxHeaderBytes < 0 {
+		v.add("server.maxHeaderBytes", "must not be negative")
+	}
+
+	tls := server.TLS
+	if tls == (TLSConfig{}) {
+		return
+	}
+
+	if tls.CertFile == "" || tls.KeyFile == "" {
+		v.add("server.tls", "certFile and keyFile are both required")
+	}
+
+	v.oneOf("server.tls.clientAuth", tls.ClientAuth, clientAuthModes)
+	v.oneOf("server.tls.minVersion", tls.MinVersion, tlsVersions)
+
+	if tls.ClientAuth != "" && tls.ClientCAFile == "" {
+		v.add("server.tls.clientAuth", "requires clientCaFile")
+	}
+}
+
+func (v *validator) validateRepositories(config *Config) {
+	if len(config.Repositories) == 0 {
+		v.add("repositories", "at least one repository is required")
+	}
+
+	seen := map[string]int{}
+
+	for index, repo := range config.Repositories {
+		path := fmt.Sprintf("repositories[%d]", index)
+
+		switch first, duplicate := seen[repo.Name]; {
+		case repo.Name == "":
+			v.add(path+".name", "is required")
+		case !repositoryNameRe.MatchString(repo.Name):
+			v.add(path+".name", "must contain only letters, digits, '.', '_' and '-', got %q", repo.Name)
+		case repo.Name == ReservedRepositoryName:
+			v.add(path+".name", "%q is reserved for the search endpoint", repo.Name)
+		case duplicate:
+			v.add(path+".name", "duplicates repositories[%d].name %q", first, repo.Name)
+		default:
+			seen[repo.Name] = index
+		}
+
+		if repo.Provider == "" {
+			v.add(path+".provider", "is required")
+		} else if _, ok := config.Providers[repo.Provider]; !ok {
+			v.add(path+".provider", "refers to undefined provider %q", repo.Provider)
+		}
+
+		v.oneOf(path+".mode", repo.Mode, repositoryModes)
+		v.oneOf(path+".invalidVersions", repo.InvalidVersions, invalidVersions)
+		v.oneOf(path+".scheme", repo.Scheme, versionSchemes)
+
+		if repo.Layout != "" && repo.LayoutRegex != "" {
+			v.add(path, "layout and layoutRegex are mutually exclusive")
+		}
+
+		if repo.Layout == "" || repo.LayoutRegex == "" {
+			layoutPath := path + ".layout"
+			if repo.LayoutRegex != "" {
+				layoutPath = path + ".layoutRegex"
+			}
+
+			// The layout is parsed as the server parses it, so validate rejects what serve would.
+			if _, err := providers.NewLayout(repo.Layout, repo.LayoutRegex); err != nil {
+				v.add(layoutPath, "%v", err)
+			}
+		}
+
+		if repo.Access.Anonymous != "" {
+			v.oneOf(path+".access.anonymous", strings.ToLower(repo.Access.Anonymous), roleNames)
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(repo.Access.Roles)) {
+			v.oneOf(path+".access.roles."+name, strings.ToLower(repo.Access.Roles[name]), roleNames)
+		}
+
+		v.nonNegative(path+".download.presignExpiry", repo.Download.PresignExpiry)
+		v.nonNegative(path+".cache.ttl", repo.Cache.TTL)
+		v.nonNegative(path+".cache.staleTtl", repo.Cache.StaleTTL)
+		v.nonNegative(path+".timeout", repo.Timeout)
+
+		if repo.Cache.MaxEntries < 0 {
+			v.add(path+".cache.maxEntries", "must not be negative")
+		}
+
+		if repo.Cache.StaleTTL > 0 && repo.Cache.TTL == 0 {
+			v.add(path+".cache.staleTtl", "requires cache.ttl")
+		}
+	}
+}
+
+func (v *validator) validateProviders(providers map[string]interface{}) {
+	for _, name := range slices.Sorted(maps.Keys(providers)) {
+		path := "providers." + name
+
+		switch conf := providers[name].(type) {
+		case LocalProviderConfig:
+			if conf.Path == "" {
+				v.add(path+".path", "is required")
+			}
+		case S3ProviderConfig:
+			if conf.Bucket == "" {
+				v.add(path+".bucket", "is required")
+			}
+
+			if (conf.AccessKey == "") != (conf.SecretKey == "") {
+				v.add(path, "accessKey and secretKey must be set together")
+			}
+		default:
+			v.add(path, "has unsupported type %T", conf)
+		}
+	}
+}
+
+func (v *validator) validateAuth(config *Config) {
+	auth := config.Auth
+	seen := map[string]bool{}
+
+	for index, user := range auth.Users {
+		path := fmt.Sprintf("auth.users[%d]", index)
+
+		switch {
+		case user.Name == "":
+			v.add(path+".name", "is required")
+		case seen[user.Name]:
+			v.add(path+".name", "duplicates user %q", user.Name)
+		default:
+			seen[user.Name] = true
+		}
+
+		if user.PasswordHash == "" && len(user.TokenHashes) == 0 {
+			v.add(path, "passwordHash or tokenHashes is required")
+		}
+	}
+
+	oidc := auth.OIDC
+	if oidc.Issuer == "" {
+		if oidc.JWKSFile != "" || oidc.JWKSURL != "" || len(oidc.Rules) > 0 {
+			v.add("auth.oidc.issuer", "is required when OIDC is configured")
+		}
+
+		return
+	}
+
+	if oidc.Audience == "" {
+		v.add("auth.oidc.audience", "is required, since other relying parties may hold tokens of the same issuer")
+	}
+
+	if (oidc.JWKSFile == "") == (oidc.JWKSURL == "") {
+		v.add("auth.oidc", "exactly one of jwksFile and jwksUrl is required")
+	}
+
+	// The keys are loaded on startup, so a file that cannot be read keeps the server from starting.
+	if oidc.JWKSFile != "" {
+		if file, err := os.Open(oidc.JWKSFile); err != nil {
+			v.add("auth.oidc.jwksFile", "cannot be read: %v", err)
+		} else {
+			file.Close()
+		}
+	}
+
+	v.nonNegative("auth.oidc.jwksRefresh", oidc.JWKSRefresh)
+
+	repositories := map[string]bool{}
+	for _, repo := range config.Repositories {
+		repositories[repo.Name] = true
+	}
+
+	for index, rule := range oidc.Rules {
+		for _, repository := range slices.Sorted(maps.Keys(rule.Repositories)) {
+			path := fmt.Sprintf("auth.oidc.rules[%d].repositories.%s", index, repository)
+
+			if !repositories[repository] {
+				v.add(path, "refers to undefined repository %q", repository)
+			}
+
+			v.oneOf(path, strings.ToLower(rule.Repositories[repository]), roleNames)
+		}
+	}
+}
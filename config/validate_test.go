@@ -0,0 +1,233 @@
+package config_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mauhlik/go-index/config"
+)
+
+func TestValidateReportsEveryError(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Repositories: []config.RepositoryConfig{
+			{Name: "files", Provider: "missing"},
+			{Name: "files", Provider: "local", Mode: "proxy"},
+			{Name: "", Provider: "s3", Access: config.AccessConfig{Roles: map[string]string{"ci": "owner"}}},
+			{Name: config.ReservedRepositoryName, Provider: "local"},
+		},
+		Providers: map[string]interface{}{
+			"local": config.LocalProviderConfig{Type: "local"},
+			"s3":    config.S3ProviderConfig{Type: "s3", Bucket: "artifacts", AccessKey: "access"},
+		},
+	}
+
+	err := cfg.Validate()
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, config.ErrInvalidConfig) {
+		t.Fatalf("Validate returned %v; want a *ValidationError", err)
+	}
+
+	paths := make([]string, 0, len(validationErr.Errors))
+	for _, fieldError := range validationErr.Errors {
+		paths = append(paths, fieldError.Path)
+	}
+
+	want := []string{
+		"repositories[0].provider",
+		"repositories[1].name",
+		"repositories[1].mode",
+		"repositories[2].name",
+		"repositories[2].access.roles.ci",
+		"repositories[3].name",
+		"providers.local.path",
+		"providers.s3",
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("Validate reported %v; want %v", paths, want)
+	}
+}
+
+// TestValidateRejectsWhatServeRejects covers settings that are only checked when the server starts them.
+func TestValidateRejectsWhatServeRejects(t *testing.T) {
+	t.Parallel()
+
+	oidc := config.OIDCConfig{Issuer: "https://issuer.example.com", Audience: "go-index",
+		JWKSURL: "https://issuer.example.com/jwks"}
+
+	testCases := []struct {
+		name   string
+		change func(cfg *config.Config)
+		path   string
+	}{
+		{"layout without version", func(cfg *config.Config) {
+			cfg.Repositories[0].Layout = "{module}/{artifact}/file.tgz"
+		}, "repositories[0].layout"},
+		{"layout regex without version", func(cfg *config.Config) {
+			cfg.Repositories[0].LayoutRegex = `^(?P<module>[^/]+)/(?P<artifact>[^/]+)/file\.tgz$`
+		}, "repositories[0].layoutRegex"},
+		{"missing JWKS file", func(cfg *config.Config) {
+			cfg.Auth.OIDC = oidc
+			cfg.Auth.OIDC.JWKSURL = ""
+			cfg.Auth.OIDC.JWKSFile = "/nonexistent/jwks.json"
+		}, "auth.oidc.jwksFile"},
+		{"OIDC without audience", func(cfg *config.Config) {
+			cfg.Auth.OIDC = oidc
+			cfg.Auth.OIDC.Audience = ""
+		}, "auth.oidc.audience"},
+		{"OIDC rule for undefined repository", func(cfg *config.Config) {
+			cfg.Auth.OIDC = oidc
+			cfg.Auth.OIDC.Rules = []config.OIDCRuleConfig{{Repositories: map[string]string{"missing": "read"}}}
+		}, "auth.oidc.rules[0].repositories.missing"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &config.Config{
+				Repositories: []config.RepositoryConfig{{Name: "files", Provider: "local"}},
+				Providers:    map[string]interface{}{"local": config.LocalProviderConfig{Type: "local", Path: "/srv"}},
+			}
+			testCase.change(cfg)
+
+			var validationErr *config.ValidationError
+			if err := cfg.Validate(); !errors.As(err, &validationErr) || len(validationErr.Errors) != 1 ||
+				validationErr.Errors[0].Path != testCase.path {
+				t.Errorf("Validate returned %v; want one error at %s", err, testCase.path)
+			}
+		})
+	}
+}
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		content string
+		path    string
+	}{
+		"top level": {"repositories:\n  - name: files\n    provider: local\n    publsh: true\n" +
+			"providers:\n  local:\n    type: local\n    path: /srv\n", "repositories[0].publsh"},
+		"provider": {"repositories:\n  - name: files\n    provider: local\n" +
+			"providers:\n  local:\n    type: local\n    path: /srv\n    bucket: artifacts\n", "providers.local.bucket"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := config.LoadConfig(writeConfig(t, t.TempDir(), testCase.content))
+
+			var validationErr *config.ValidationError
+			if !errors.As(err, &validationErr) || !errors.Is(err, config.ErrUnknownKey) ||
+				len(validationErr.Errors) != 1 || validationErr.Errors[0].Path != testCase.path {
+				t.Errorf("LoadConfig returned %v; want an unknown key at %s", err, testCase.path)
+			}
+		})
+	}
+}
+
+func TestLoadConfigReportsLoadAndValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := config.LoadConfig(writeConfig(t, t.TempDir(), `
+repositories:
+  - name: ${GO_INDEX_UNSET_VARIABLE}
+    provider: local
+    publsh: true
+  - name: files
+    provider: s3
+providers:
+  local:
+    type: local
+`))
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, config.ErrMissingVariable) {
+		t.Fatalf("LoadConfig returned %v; want a *ValidationError", err)
+	}
+
+	paths := make([]string, 0, len(validationErr.Errors))
+	for _, fieldError := range validationErr.Errors {
+		paths = append(paths, fieldError.Path)
+	}
+
+	want := []string{"repositories[0].publsh", "repositories[0].name", "repositories[1].provider", "providers.local.path"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("LoadConfig reported %v; want %v", paths, want)
+	}
+}
+
+// TestSchemaMatchesConfig keeps schema.json in step with the configuration structs.
+func TestSchemaMatchesConfig(t *testing.T) {
+	t.Parallel()
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(config.Schema, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	definitions, _ := schema["$defs"].(map[string]interface{})
+
+	resolve := func(node map[string]interface{}) map[string]interface{} {
+		if ref, ok := node["$ref"].(string); ok {
+			definition, _ := definitions[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+
+			return definition
+		}
+
+		return node
+	}
+
+	var compare func(path string, structType reflect.Type, node map[string]interface{})
+
+	compare = func(path string, structType reflect.Type, node map[string]interface{}) {
+		node = resolve(node)
+		properties, _ := node["properties"].(map[string]interface{})
+
+		if node["additionalProperties"] != false {
+			t.Errorf("%s does not reject unknown keys", path)
+		}
+
+		for index := range structType.NumField() {
+			field := structType.Field(index)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				t.Errorf("%s.%s is missing from the schema", path, name)
+
+				continue
+			}
+
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Slice {
+				fieldType = fieldType.Elem()
+				property, _ = property["items"].(map[string]interface{})
+			}
+
+			if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(config.Duration(0)) {
+				compare(path+"."+name, fieldType, property)
+			}
+		}
+
+		if len(properties) != structType.NumField() {
+			t.Errorf("%s has %d schema properties for %d fields", path, len(properties), structType.NumField())
+		}
+	}
+
+	compare("config", reflect.TypeOf(config.Config{}), schema)
+	compare("providers.local", reflect.TypeOf(config.LocalProviderConfig{}), resolve(map[string]interface{}{
+		"$ref": "#/$defs/localProvider",
+	}))
+	compare("providers.s3", reflect.TypeOf(config.S3ProviderConfig{}), resolve(map[string]interface{}{
+		"$ref": "#/$defs/s3Provider",
+	}))
+}
@@ -0,0 +1,77 @@
+// Package apierror writes the JSON body of failed API requests and tags every request with an ID that
+// the body, the response headers and the logs share.
+package apierror
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	requestIDKey = "requestID"
+)
+
+// Codes of Response, stable for clients to match on.
+const (
+	CodeBadRequest          = "bad_request"
+	CodeInvalidVersion      = "invalid_version"
+	CodeInvalidConstraint   = "invalid_constraint"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeModuleNotFound      = "module_not_found"
+	CodeArtifactNotFound    = "artifact_not_found"
+	CodeVersionNotFound     = "version_not_found"
+	CodeFileNotFound        = "file_not_found"
+	CodeAmbiguousDownload   = "ambiguous_download"
+	CodeConflict            = "conflict"
+	CodeNotImplemented      = "not_implemented"
+	CodeProviderUnavailable = "provider_unavailable"
+	CodeUnavailable         = "unavailable"
+	CodeTimeout             = "timeout"
+	CodeInternal            = "internal_error"
+)
+
+// requestIDPattern bounds the IDs accepted from clients or proxies, so they are safe to log and echo.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type Response struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// RequestID keeps a valid X-Request-ID of the request or generates one, and echoes it in the response.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+
+		ctx.Set(requestIDKey, id)
+		ctx.Header(RequestIDHeader, id)
+		ctx.Next()
+	}
+}
+
+func RequestIDFrom(ctx *gin.Context) string {
+	return ctx.GetString(requestIDKey)
+}
+
+// Abort ends the request with an error body.
+func Abort(ctx *gin.Context, status int, code, message string) {
+	ctx.AbortWithStatusJSON(status, Response{Code: code, Message: message, RequestID: RequestIDFrom(ctx)})
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidUser        = errors.New("invalid user configuration")
+)
+
+// Identity is an authenticated caller. Grants holds per-repository roles carried by the credential
+// itself rather than configured on the repository. A federated identity comes from an external issuer
+// whose names the configuration does not control, so it holds its Grants and nothing else.
+type Identity struct {
+	Name      string
+	Grants    map[string]Role
+	Federated bool
+}
+
+// Authenticator returns a nil identity and no error when the request carries no credentials it understands.
+type Authenticator interface {
+	Authenticate(request *http.Request) (*Identity, error)
+}
+
+type User struct {
+	Name string
+	// PasswordHash is a bcrypt hash used for HTTP basic authentication.
+	PasswordHash string
+	// TokenHashes are hex encoded SHA-256 hashes of API tokens.
+	TokenHashes []string
+}
+
+// StaticAuthenticator accepts API tokens as bearer tokens or basic passwords, and bcrypt passwords
+// over basic authentication. Only hashes are kept in memory.
+type StaticAuthenticator struct {
+	passwords map[string][]byte
+	tokens    map[string]string
+	// dummy is compared against for unknown user names, so they take as long to reject as wrong passwords.
+	dummy []byte
+}
+
+func NewStaticAuthenticator(users []User) (*StaticAuthenticator, error) {
+	authenticator := &StaticAuthenticator{passwords: map[string][]byte{}, tokens: map[string]string{}, dummy: nil}
+	cost := 0
+
+	for _, user := range users {
+		if user.Name == "" || user.Name == AnyUser {
+			return nil, fmt.Errorf("%w: invalid name %q", ErrInvalidUser, user.Name)
+		}
+
+		if user.PasswordHash != "" {
+			userCost, err := bcrypt.Cost([]byte(user.PasswordHash))
+			if err != nil {
+				return nil, fmt.Errorf("%w: password hash of %s is not bcrypt: %w", ErrInvalidUser, user.Name, err)
+			}
+
+			authenticator.passwords[user.Name] = []byte(user.PasswordHash)
+			cost = max(cost, userCost)
+		}
+
+		for _, tokenHash := range user.TokenHashes {
+			decoded, err := hex.DecodeString(strings.TrimPrefix(tokenHash, "sha256:"))
+			if err != nil || len(decoded) != sha256.Size {
+				return nil, fmt.Errorf("%w: token hash of %s is not a hex SHA-256 digest", ErrInvalidUser, user.Name)
+			}
+
+			authenticator.tokens[string(decoded)] = user.Name
+		}
+	}
+
+	if cost > 0 {
+		// The dummy costs as much as the most expensive configured hash; its password is never known.
+		secret := make([]byte, sha256.Size)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("generating dummy password: %w", err)
+		}
+
+		dummy, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), cost)
+		if err != nil {
+			return nil, fmt.Errorf("hashing dummy password: %w", err)
+		}
+
+		authenticator.dummy = dummy
+	}
+
+	return authenticator, nil
+}
+
+func (a *StaticAuthenticator) Authenticate(request *http.Request) (*Identity, error) {
+	if username, password, ok := request.BasicAuth(); ok {
+		return a.authenticateBasic(username, password)
+	}
+
+	token, ok := BearerToken(request)
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	if name, ok := a.lookupToken(token); ok {
+		return &Identity{Name: name, Grants: nil, Federated: false}, nil
+	}
+
+	return nil, ErrInvalidCredentials
+}
+
+func (a *StaticAuthenticator) authenticateBasic(username, password string) (*Identity, error) {
+	hash, ok := a.passwords[username]
+	if !ok {
+		hash = a.dummy
+	}
+
+	if hash != nil && bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil && ok {
+		return &Identity{Name: username, Grants: nil, Federated: false}, nil
+	}
+
+	// Tools that only speak basic authentication (netrc, go get) send the API token as the password.
+	if name, ok := a.lookupToken(password); ok && name == username {
+		return &Identity{Name: name, Grants: nil, Federated: false}, nil
+	}
+
+	return nil, ErrInvalidCredentials
+}
+
+func (a *StaticAuthenticator) lookupToken(token string) (string, bool) {
+	sum := sha256.Sum256([]byte(token))
+	name, ok := a.tokens[string(sum[:])]
+
+	return name, ok
+}
+
+func BearerToken(request *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// Chain tries each authenticator in order and returns the first identity or error.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(request *http.Request) (*Identity, error) {
+	for _, authenticator := range c {
+		identity, err := authenticator.Authenticate(request)
+		if identity != nil || err != nil {
+			return identity, err
+		}
+	}
+
+	return nil, nil //nolint:nilnil
+}
@@ -0,0 +1,143 @@
+package auth_test
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mauhlik/go-index/internal/go-index/auth"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func newTestAuthenticator(t *testing.T) *auth.StaticAuthenticator {
+	t.Helper()
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	authenticator, err := auth.NewStaticAuthenticator([]auth.User{
+		{Name: "alice", PasswordHash: string(passwordHash), TokenHashes: nil},
+		{Name: "ci", PasswordHash: "", TokenHashes: []string{"sha256:" + hashToken("ci-token")}},
+	})
+	if err != nil {
+		t.Fatalf("NewStaticAuthenticator returned an error: %v", err)
+	}
+
+	return authenticator
+}
+
+func TestStaticAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	authenticator := newTestAuthenticator(t)
+
+	testCases := []struct {
+		name     string
+		setup    func(request *http.Request)
+		identity string
+		err      error
+	}{
+		{"anonymous", func(*http.Request) {}, "", nil},
+		{"basic password", func(r *http.Request) { r.SetBasicAuth("alice", "secret") }, "alice", nil},
+		{"wrong password", func(r *http.Request) { r.SetBasicAuth("alice", "nope") }, "", auth.ErrInvalidCredentials},
+		{"unknown user", func(r *http.Request) { r.SetBasicAuth("mallory", "secret") }, "", auth.ErrInvalidCredentials},
+		{"bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer ci-token") }, "ci", nil},
+		{"token as password", func(r *http.Request) { r.SetBasicAuth("ci", "ci-token") }, "ci", nil},
+		{"token of other user", func(r *http.Request) { r.SetBasicAuth("alice", "ci-token") }, "",
+			auth.ErrInvalidCredentials},
+		{"unknown token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer other") }, "",
+			auth.ErrInvalidCredentials},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			testCase.setup(request)
+
+			identity, err := authenticator.Authenticate(request)
+			if !errors.Is(err, testCase.err) {
+				t.Fatalf("Authenticate returned %v; want %v", err, testCase.err)
+			}
+
+			if name := identityName(identity); name != testCase.identity {
+				t.Errorf("Authenticate returned identity %q; want %q", name, testCase.identity)
+			}
+		})
+	}
+}
+
+func TestNewStaticAuthenticatorInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, user := range []auth.User{
+		{Name: "", PasswordHash: "", TokenHashes: nil},
+		{Name: "bob", PasswordHash: "plaintext", TokenHashes: nil},
+		{Name: "bob", PasswordHash: "", TokenHashes: []string{"not-hex"}},
+	} {
+		if _, err := auth.NewStaticAuthenticator([]auth.User{user}); !errors.Is(err, auth.ErrInvalidUser) {
+			t.Errorf("NewStaticAuthenticator(%+v) returned %v; want %v", user, err, auth.ErrInvalidUser)
+		}
+	}
+}
+
+func TestClientCertAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	chain := func(commonName string) *tls.ConnectionState {
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: commonName}}}}}
+	}
+
+	testCases := []struct {
+		name     string
+		state    *tls.ConnectionState
+		identity string
+		err      error
+	}{
+		{"plain HTTP", nil, "", nil},
+		{"unverified", &tls.ConnectionState{}, "", nil},
+		{"verified", chain("ci"), "ci", nil},
+		{"no common name", chain(""), "", auth.ErrInvalidCredentials},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.TLS = testCase.state
+
+			identity, err := auth.ClientCertAuthenticator{}.Authenticate(request)
+			if !errors.Is(err, testCase.err) {
+				t.Fatalf("Authenticate returned %v; want %v", err, testCase.err)
+			}
+
+			if name := identityName(identity); name != testCase.identity {
+				t.Errorf("Authenticate returned identity %q; want %q", name, testCase.identity)
+			}
+		})
+	}
+}
+
+func identityName(identity *auth.Identity) string {
+	if identity == nil {
+		return ""
+	}
+
+	return identity.Name
+}
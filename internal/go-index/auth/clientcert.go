@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ClientCertAuthenticator identifies callers by the common name of a verified TLS client certificate.
+// Certificates are only trusted once the TLS handshake has verified them against the client CAs.
+type ClientCertAuthenticator struct{}
+
+func (ClientCertAuthenticator) Authenticate(request *http.Request) (*Identity, error) {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 || len(request.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	subject := request.TLS.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" || subject.CommonName == AnyUser {
+		return nil, fmt.Errorf("%w: client certificate %s has no usable common name", ErrInvalidCredentials, subject)
+	}
+
+	return &Identity{Name: subject.CommonName, Grants: nil, Federated: false}, nil
+}
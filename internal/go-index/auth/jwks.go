@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultJWKSRefresh = time.Hour
+	// jwksMinRefetch bounds how often keys are reloaded, capped by the refresh interval; unknown key ids
+	// seen within it of the last reload are rejected without fetching again.
+	jwksMinRefetch   = time.Minute
+	jwksFetchTimeout = 10 * time.Second
+)
+
+var (
+	ErrInvalidJWKS = errors.New("invalid JWKS")
+	ErrUnknownKey  = errors.New("unknown signing key")
+)
+
+// JWKS loads signing keys from a file or URL and keeps them cached for the refresh interval.
+// A failed reload keeps serving the previous keys. Reloads run outside the lock and at most one at a
+// time: stale keys are served while they run, and only callers waiting for an unknown key id block.
+type JWKS struct {
+	source     string
+	refresh    time.Duration
+	minRefetch time.Duration
+	client     *http.Client
+	logger     *logrus.Logger
+
+	mutex       sync.Mutex
+	keys        jose.JSONWebKeySet
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	// loading is closed when the running reload ends; nil when none runs.
+	loading chan struct{}
+}
+
+func NewJWKS(source string, refresh time.Duration, logger *logrus.Logger) (*JWKS, error) {
+	if refresh <= 0 {
+		refresh = defaultJWKSRefresh
+	}
+
+	jwks := &JWKS{
+		source:      source,
+		refresh:     refresh,
+		minRefetch:  min(refresh, jwksMinRefetch),
+		client:      &http.Client{Timeout: jwksFetchTimeout}, //nolint:exhaustruct
+		logger:      logger,
+		mutex:       sync.Mutex{},
+		keys:        jose.JSONWebKeySet{Keys: nil},
+		fetchedAt:   time.Time{},
+		attemptedAt: time.Now(),
+		loading:     nil,
+	}
+
+	keys, err := jwks.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	jwks.keys = keys
+	jwks.fetchedAt = jwks.attemptedAt
+
+	return jwks, nil
+}
+
+// Key returns the public key with keyID, reloading the set when it is stale or does not hold the key.
+func (j *JWKS) Key(ctx context.Context, keyID string) (*jose.JSONWebKey, error) {
+	j.mutex.Lock()
+
+	now := time.Now()
+	key, found := j.lookup(keyID)
+	stale := now.Sub(j.fetchedAt) > j.refresh
+	canReload := j.loading != nil || now.Sub(j.attemptedAt) > j.minRefetch
+
+	var loading chan struct{}
+	if (stale || !found) && canReload {
+		loading = j.startReload(now)
+	}
+
+	j.mutex.Unlock()
+
+	if found {
+		return key, nil
+	}
+
+	if loading == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+
+	select {
+	case <-loading:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to wait for JWKS reload: %w", ctx.Err())
+	}
+
+	j.mutex.Lock()
+	key, found = j.lookup(keyID)
+	j.mutex.Unlock()
+
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+
+	return key, nil
+}
+
+// lookup accepts an empty key id for a set holding a single key. It must be called with the mutex held.
+func (j *JWKS) lookup(keyID string) (*jose.JSONWebKey, bool) {
+	if keyID == "" && len(j.keys.Keys) == 1 {
+		return &j.keys.Keys[0], true
+	}
+
+	for index := range j.keys.Keys {
+		if j.keys.Keys[index].KeyID == keyID {
+			return &j.keys.Keys[index], true
+		}
+	}
+
+	return nil, false
+}
+
+// startReload joins the running reload or starts one. It must be called with the mutex held.
+func (j *JWKS) startReload(now time.Time) chan struct{} {
+	if j.loading != nil {
+		return j.loading
+	}
+
+	loading := make(chan struct{})
+	j.loading = loading
+	j.attemptedAt = now
+
+	go func() {
+		keys, err := j.fetch()
+
+		j.mutex.Lock()
+		defer j.mutex.Unlock()
+
+		if err != nil {
+			j.logger.WithError(err).Warnf("Failed to reload JWKS from %s, keeping cached keys", j.source)
+		} else {
+			j.keys = keys
+			j.fetchedAt = now
+		}
+
+		j.loading = nil
+		close(loading)
+	}()
+
+	return loading
+}
+
+// fetch reads and parses the key set; only public signing keys are kept.
+func (j *JWKS) fetch() (jose.JSONWebKeySet, error) {
+	data, err := j.read()
+	if err != nil {
+		return jose.JSONWebKeySet{}, err
+	}
+
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return jose.JSONWebKeySet{}, fmt.Errorf("%w: %w", ErrInvalidJWKS, err)
+	}
+
+	keys := make([]jose.JSONWebKey, 0, len(set.Keys))
+
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		if !key.Valid() {
+			return jose.JSONWebKeySet{}, fmt.Errorf("%w: key %q is not valid", ErrInvalidJWKS, key.KeyID)
+		}
+
+		keys = append(keys, key.Public())
+	}
+
+	return jose.JSONWebKeySet{Keys: keys}, nil
+}
+
+func (j *JWKS) read() ([]byte, error) {
+	if !isURL(j.source) {
+		data, err := os.ReadFile(j.source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+
+		return data, nil
+	}
+
+	response, err := j.client.Get(j.source) //nolint:noctx
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w: status %d", ErrInvalidJWKS, response.StatusCode)
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	return data, nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
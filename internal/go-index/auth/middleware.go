@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/apierror"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	identityKey = "identity"
+	realm       = `Basic realm="go-index"`
+)
+
+// Middleware rejects requests whose identity holds less than required on repository. The identity is
+// resolved once per request and can be read back with IdentityFrom.
+func Middleware(repository string, authenticator Authenticator, policy Policy, required Role,
+	logger *logrus.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		caller, ok := identify(ctx, authenticator, "repository "+repository, logger)
+		if !ok {
+			return
+		}
+
+		if policy.RoleFor(repository, caller) >= required {
+			ctx.Next()
+
+			return
+		}
+
+		if caller == nil {
+			ctx.Header("WWW-Authenticate", realm)
+			apierror.Abort(ctx, http.StatusUnauthorized, apierror.CodeUnauthorized, "authentication required")
+
+			return
+		}
+
+		logger.Warnf("Denied %s access to repository %s for %s", required, repository, caller.Name)
+		apierror.Abort(ctx, http.StatusForbidden, apierror.CodeForbidden,
+			"insufficient permissions: "+required.String()+" role required")
+	}
+}
+
+// Identify resolves the identity for IdentityFrom without requiring any role; only invalid credentials
+// are rejected, anonymous requests pass.
+func Identify(authenticator Authenticator, logger *logrus.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := identify(ctx, authenticator, ctx.Request.URL.Path, logger); ok {
+			ctx.Next()
+		}
+	}
+}
+
+// identify authenticates the request once, aborting it with 401 on invalid credentials.
+func identify(ctx *gin.Context, authenticator Authenticator, target string, logger *logrus.Logger) (*Identity, bool) {
+	identity, resolved := ctx.Get(identityKey)
+	if !resolved {
+		var err error
+
+		identity, err = authenticator.Authenticate(ctx.Request)
+		if err != nil {
+			logger.WithError(err).Warnf("Rejected credentials for %s", target)
+			ctx.Header("WWW-Authenticate", realm)
+			apierror.Abort(ctx, http.StatusUnauthorized, apierror.CodeUnauthorized, err.Error())
+
+			return nil, false
+		}
+
+		ctx.Set(identityKey, identity)
+	}
+
+	caller, _ := identity.(*Identity)
+
+	return caller, true
+}
+
+func IdentityFrom(ctx *gin.Context) *Identity {
+	identity, _ := ctx.Get(identityKey)
+	caller, _ := identity.(*Identity)
+
+	return caller
+}
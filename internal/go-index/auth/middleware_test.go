@@ -0,0 +1,90 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/auth"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	policy, err := auth.NewPolicy("none", map[string]string{"alice": "read", "ci": "publish"})
+	if err != nil {
+		t.Fatalf("NewPolicy returned an error: %v", err)
+	}
+
+	authenticator := newTestAuthenticator(t)
+	logger := logrus.New()
+
+	router := gin.New()
+	group := router.Group("/api/internal")
+	group.Use(auth.Middleware("internal", authenticator, policy, auth.RoleRead, logger))
+	group.GET("/file", func(ctx *gin.Context) { ctx.String(http.StatusOK, auth.IdentityFrom(ctx).Name) })
+	group.PUT("/file", auth.Middleware("internal", authenticator, policy, auth.RolePublish, logger),
+		func(ctx *gin.Context) { ctx.Status(http.StatusCreated) })
+	group.DELETE("/file", auth.Middleware("internal", authenticator, policy, auth.RoleAdmin, logger),
+		func(ctx *gin.Context) { ctx.Status(http.StatusNoContent) })
+
+	testCases := []struct {
+		method string
+		setup  func(request *http.Request)
+		status int
+	}{
+		{http.MethodGet, func(*http.Request) {}, http.StatusUnauthorized},
+		{http.MethodGet, func(r *http.Request) { r.SetBasicAuth("alice", "wrong") }, http.StatusUnauthorized},
+		{http.MethodGet, func(r *http.Request) { r.SetBasicAuth("alice", "secret") }, http.StatusOK},
+		{http.MethodPut, func(r *http.Request) { r.SetBasicAuth("alice", "secret") }, http.StatusForbidden},
+		{http.MethodPut, func(r *http.Request) { r.Header.Set("Authorization", "Bearer ci-token") }, http.StatusCreated},
+		{http.MethodDelete, func(r *http.Request) { r.Header.Set("Authorization", "Bearer ci-token") },
+			http.StatusForbidden},
+	}
+
+	for _, testCase := range testCases {
+		request := httptest.NewRequest(testCase.method, "/api/internal/file", nil)
+		testCase.setup(request)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != testCase.status {
+			t.Errorf("%s with %v returned %d; want %d", testCase.method, request.Header, recorder.Code, testCase.status)
+		}
+	}
+}
+
+func TestPolicyRoleFor(t *testing.T) {
+	t.Parallel()
+
+	policy, err := auth.NewPolicy("", map[string]string{"*": "read", "admin": "admin"})
+	if err != nil {
+		t.Fatalf("NewPolicy returned an error: %v", err)
+	}
+
+	if role := policy.RoleFor("repo", nil); role != auth.RoleRead {
+		t.Errorf("Anonymous role is %s; want %s", role, auth.RoleRead)
+	}
+
+	granted := &auth.Identity{Name: "ci", Grants: map[string]auth.Role{"repo": auth.RolePublish}}
+	if role := policy.RoleFor("repo", granted); role != auth.RolePublish {
+		t.Errorf("Granted role is %s; want %s", role, auth.RolePublish)
+	}
+
+	if role := policy.RoleFor("repo", &auth.Identity{Name: "admin", Grants: nil}); role != auth.RoleAdmin {
+		t.Errorf("Admin role is %s; want %s", role, auth.RoleAdmin)
+	}
+
+	federated := &auth.Identity{Name: "admin", Grants: map[string]auth.Role{"other": auth.RolePublish}, Federated: true}
+	if role := policy.RoleFor("repo", federated); role != auth.RoleRead {
+		t.Errorf("Federated role is %s; want only the anonymous %s", role, auth.RoleRead)
+	}
+
+	if _, err := auth.NewPolicy("owner", nil); err == nil {
+		t.Error("Expected an error for an unknown role")
+	}
+}
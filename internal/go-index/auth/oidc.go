@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	defaultUsernameClaim = "sub"
+	// oidcNamePrefix keeps token subjects apart from configured user names in logs.
+	oidcNamePrefix = "oidc:"
+	clockSkew      = time.Minute
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrInvalidOIDCRule  = errors.New("invalid OIDC rule")
+	ErrOIDCIssuerNeeded = errors.New("OIDC issuer is required")
+	// ErrOIDCAudienceNeeded is returned without an audience: a shared issuer, such as a CI or cloud identity
+	// provider, mints tokens for every relying party, and only the audience tells ours apart.
+	ErrOIDCAudienceNeeded = errors.New("OIDC audience is required")
+)
+
+// signingAlgorithms are the asymmetric algorithms accepted for tokens; "none" and HMAC never are.
+var signingAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// OIDCRule grants roles on repositories to tokens whose claims match every entry of Claims.
+// Claim patterns use path.Match syntax, so "refs/heads/*" matches any branch.
+type OIDCRule struct {
+	Claims       map[string]string
+	Repositories map[string]Role
+}
+
+type OIDCConfig struct {
+	Issuer        string
+	Audience      string
+	UsernameClaim string
+	Rules         []OIDCRule
+}
+
+// OIDCAuthenticator validates bearer JWTs against a JWKS and turns matching claim rules into grants.
+// Bearer tokens that are not JWTs are left to other authenticators.
+type OIDCAuthenticator struct {
+	config OIDCConfig
+	keys   *JWKS
+	now    func() time.Time
+}
+
+func NewOIDCAuthenticator(config OIDCConfig, keys *JWKS) (*OIDCAuthenticator, error) {
+	if config.Issuer == "" {
+		return nil, ErrOIDCIssuerNeeded
+	}
+
+	if config.Audience == "" {
+		return nil, ErrOIDCAudienceNeeded
+	}
+
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = defaultUsernameClaim
+	}
+
+	for _, rule := range config.Rules {
+		for claim, pattern := range rule.Claims {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%w: claim %s: %w", ErrInvalidOIDCRule, claim, err)
+			}
+		}
+	}
+
+	return &OIDCAuthenticator{config: config, keys: keys, now: time.Now}, nil
+}
+
+func (a *OIDCAuthenticator) Authenticate(request *http.Request) (*Identity, error) {
+	token, ok := BearerToken(request)
+	if !ok || strings.Count(token, ".") != 2 { //nolint:mnd
+		return nil, nil //nolint:nilnil
+	}
+
+	claims, err := a.verify(request.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	name, _ := claims[a.config.UsernameClaim].(string)
+	if name == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, a.config.UsernameClaim)
+	}
+
+	grants := map[string]Role{}
+	matched := false
+
+	for _, rule := range a.config.Rules {
+		if !rule.matches(claims) {
+			continue
+		}
+
+		matched = true
+
+		for repository, role := range rule.Repositories {
+			grants[repository] = max(grants[repository], role)
+		}
+	}
+
+	// Anyone may hold a valid token of a shared issuer, so only the rules decide who is let in.
+	if !matched {
+		return nil, fmt.Errorf("%w: no rule matches the token of %s", ErrInvalidToken, name)
+	}
+
+	return &Identity{Name: oidcNamePrefix + name, Grants: grants, Federated: true}, nil
+}
+
+func (a *OIDCAuthenticator) verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	parsed, err := jwt.ParseSigned(token, signingAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	key, err := a.keys.Key(ctx, parsed.Headers[0].KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	var (
+		registered jwt.Claims
+		claims     map[string]interface{}
+	)
+
+	if err := parsed.Claims(key, &registered, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if err := a.validateClaims(registered); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (a *OIDCAuthenticator) validateClaims(claims jwt.Claims) error {
+	if claims.Expiry == nil {
+		return fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+
+	expected := jwt.Expected{
+		Issuer:      a.config.Issuer,
+		Subject:     "",
+		AnyAudience: jwt.Audience{a.config.Audience},
+		ID:          "",
+		Time:        a.now(),
+	}
+
+	if err := claims.ValidateWithLeeway(expected, clockSkew); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return nil
+}
+
+func (r OIDCRule) matches(claims map[string]interface{}) bool {
+	for claim, pattern := range r.Claims {
+		if !claimMatches(claims[claim], pattern) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func claimMatches(value interface{}, pattern string) bool {
+	if values, ok := value.([]interface{}); ok {
+		for _, element := range values {
+			if claimMatches(element, pattern) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if value == nil {
+		return false
+	}
+
+	matched, _ := path.Match(pattern, fmt.Sprint(value))
+
+	return matched
+}
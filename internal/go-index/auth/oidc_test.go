@@ -0,0 +1,304 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mauhlik/go-index/internal/go-index/auth"
+	"github.com/sirupsen/logrus"
+)
+
+const testIssuer = "https://ci.example.com"
+
+type testKeys struct {
+	rsa   *rsa.PrivateKey
+	ecdsa *ecdsa.PrivateKey
+	jwks  []byte
+}
+
+func encodeInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+
+	jwks, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encodeInt(rsaKey.N), "e": encodeInt(big.NewInt(int64(rsaKey.E)))},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encodeInt(ecKey.X), "y": encodeInt(ecKey.Y)},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to marshal JWKS: %v", err)
+	}
+
+	return testKeys{rsa: rsaKey, ecdsa: ecKey, jwks: jwks}
+}
+
+func (k testKeys) sign(t *testing.T, algorithm, keyID string, claims map[string]interface{}) string {
+	t.Helper()
+
+	encode := func(value interface{}) string {
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("Failed to marshal token segment: %v", err)
+		}
+
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := encode(map[string]string{"alg": algorithm, "kid": keyID, "typ": "JWT"}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+
+	switch algorithm {
+	case "RS256":
+		var err error
+
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, k.ecdsa, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestOIDCAuthenticator(t *testing.T, source string) *auth.OIDCAuthenticator {
+	t.Helper()
+
+	keys, err := auth.NewJWKS(source, 0, logrus.New())
+	if err != nil {
+		t.Fatalf("NewJWKS returned an error: %v", err)
+	}
+
+	authenticator, err := auth.NewOIDCAuthenticator(auth.OIDCConfig{
+		Issuer:        testIssuer,
+		Audience:      "go-index",
+		UsernameClaim: "",
+		Rules: []auth.OIDCRule{
+			{
+				Claims:       map[string]string{"repository": "org/app", "ref": "refs/heads/*"},
+				Repositories: map[string]auth.Role{"internal": auth.RolePublish},
+			},
+			{
+				Claims:       map[string]string{"repository": "org/*"},
+				Repositories: map[string]auth.Role{"internal": auth.RoleRead, "shared": auth.RoleRead},
+			},
+		},
+	}, keys)
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator returned an error: %v", err)
+	}
+
+	return authenticator
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	keys := newTestKeys(t)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+
+	if err := os.WriteFile(jwksFile, keys.jwks, 0600); err != nil {
+		t.Fatalf("Failed to write JWKS: %v", err)
+	}
+
+	authenticator := newTestOIDCAuthenticator(t, jwksFile)
+	now := time.Now().Unix()
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		result := map[string]interface{}{
+			"iss": testIssuer, "aud": []string{"go-index"}, "sub": "repo:org/app",
+			"repository": "org/app", "ref": "refs/heads/main", "exp": now + 300, "nbf": now - 10,
+		}
+
+		for key, value := range overrides {
+			result[key] = value
+		}
+
+		return result
+	}
+
+	authenticate := func(token string) (*auth.Identity, error) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+
+		return authenticator.Authenticate(request)
+	}
+
+	identity, err := authenticate(keys.sign(t, "RS256", "rsa", claims(nil)))
+	if err != nil {
+		t.Fatalf("Authenticate returned an error: %v", err)
+	}
+
+	if identity.Name != "oidc:repo:org/app" || !identity.Federated || identity.Grants["internal"] != auth.RolePublish ||
+		identity.Grants["shared"] != auth.RoleRead {
+		t.Errorf("Authenticate returned %+v; want publish on internal and read on shared", identity)
+	}
+
+	identity, err = authenticate(keys.sign(t, "ES256", "ec", claims(map[string]interface{}{"ref": "refs/tags/v1"})))
+	if err != nil {
+		t.Fatalf("Authenticate returned an error: %v", err)
+	}
+
+	if identity.Grants["internal"] != auth.RoleRead {
+		t.Errorf("Authenticate returned %+v; want read on internal for a tag", identity)
+	}
+
+	tampered := keys.sign(t, "RS256", "ec", claims(nil))
+
+	for name, token := range map[string]string{
+		"wrong issuer":   keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"iss": "https://other"})),
+		"wrong audience": keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"aud": "other"})),
+		"no audience":    keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"aud": nil})),
+		"expired":        keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"exp": now - 3600})),
+		"not yet valid":  keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"nbf": now + 3600})),
+		"wrong key":      tampered,
+		"unknown key":    keys.sign(t, "RS256", "missing", claims(nil)),
+		"unsupported":    keys.sign(t, "HS256", "rsa", claims(nil)),
+		"no rule":        keys.sign(t, "RS256", "rsa", claims(map[string]interface{}{"repository": "other/app"})),
+	} {
+		if _, err := authenticate(token); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("%s: Authenticate returned %v; want an invalid token error", name, err)
+		}
+	}
+
+	if identity, err := authenticate("static-token"); identity != nil || err != nil {
+		t.Errorf("Authenticate returned %v, %v for a non-JWT token; want nil, nil", identity, err)
+	}
+}
+
+func TestJWKSFromURL(t *testing.T) {
+	t.Parallel()
+
+	keys := newTestKeys(t)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write(keys.jwks)
+	}))
+	defer server.Close()
+
+	authenticator := newTestOIDCAuthenticator(t, server.URL)
+	token := keys.sign(t, "RS256", "rsa", map[string]interface{}{
+		"iss": testIssuer, "aud": "go-index", "sub": "pipeline", "repository": "org/lib",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	identity, err := auth.Chain{authenticator, newTestAuthenticator(t)}.Authenticate(request)
+	if err != nil || identity == nil || identity.Name != "oidc:pipeline" {
+		t.Errorf("Authenticate returned %+v, %v; want oidc:pipeline", identity, err)
+	}
+}
+
+func TestJWKSReloadDoesNotBlock(t *testing.T) {
+	t.Parallel()
+
+	keys := newTestKeys(t)
+	release := make(chan struct{})
+
+	var fetches atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+
+		_, _ = writer.Write(keys.jwks)
+	}))
+	defer server.Close()
+
+	const refresh = 500 * time.Millisecond
+
+	jwks, err := auth.NewJWKS(server.URL, refresh, logrus.New())
+	if err != nil {
+		t.Fatalf("NewJWKS returned an error: %v", err)
+	}
+
+	time.Sleep(refresh + 100*time.Millisecond)
+
+	// The stale key is served while its reload hangs.
+	found := make(chan error, 1)
+	go func() {
+		_, err := jwks.Key(context.Background(), "rsa")
+		found <- err
+	}()
+
+	select {
+	case err := <-found:
+		if err != nil {
+			t.Fatalf("Key of a stale key returned an error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Key of a stale key waited for the reload")
+	}
+
+	// An unknown key id joins the running reload instead of fetching again.
+	unknown := make(chan error, 1)
+	go func() {
+		_, err := jwks.Key(context.Background(), "missing")
+		unknown <- err
+	}()
+
+	for fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+
+	if err := <-unknown; !errors.Is(err, auth.ErrUnknownKey) {
+		t.Errorf("Key of an unknown key returned %v; want %v", err, auth.ErrUnknownKey)
+	}
+
+	if _, err := jwks.Key(context.Background(), "other"); !errors.Is(err, auth.ErrUnknownKey) {
+		t.Errorf("Key of an unknown key returned %v; want %v", err, auth.ErrUnknownKey)
+	}
+
+	if count := fetches.Load(); count != 2 {
+		t.Errorf("JWKS was fetched %d times; want 2", count)
+	}
+}
+
+func TestNewOIDCAuthenticatorRequiresAudience(t *testing.T) {
+	t.Parallel()
+
+	_, err := auth.NewOIDCAuthenticator(auth.OIDCConfig{Issuer: testIssuer, Audience: "", UsernameClaim: "", Rules: nil}, nil)
+	if !errors.Is(err, auth.ErrOIDCAudienceNeeded) {
+		t.Errorf("NewOIDCAuthenticator returned %v; want %v", err, auth.ErrOIDCAudienceNeeded)
+	}
+}
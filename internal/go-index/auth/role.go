@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleRead
+	RolePublish
+	RoleAdmin
+)
+
+const (
+	roleNameNone    = "none"
+	roleNameRead    = "read"
+	roleNamePublish = "publish"
+	roleNameAdmin   = "admin"
+
+	// AnyUser grants a role to every authenticated identity.
+	AnyUser = "*"
+)
+
+var ErrUnknownRole = errors.New("unknown role")
+
+func ParseRole(name string) (Role, error) {
+	switch strings.ToLower(name) {
+	case roleNameNone:
+		return RoleNone, nil
+	case roleNameRead:
+		return RoleRead, nil
+	case roleNamePublish:
+		return RolePublish, nil
+	case roleNameAdmin:
+		return RoleAdmin, nil
+	default:
+		return RoleNone, fmt.Errorf("%w: %s", ErrUnknownRole, name)
+	}
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleRead:
+		return roleNameRead
+	case RolePublish:
+		return roleNamePublish
+	case RoleAdmin:
+		return roleNameAdmin
+	default:
+		return roleNameNone
+	}
+}
+
+// Policy is the access configuration of one repository. Roles are inclusive: publish implies read.
+type Policy struct {
+	Anonymous Role
+	Roles     map[string]Role
+}
+
+// NewPolicy parses a repository's access section; anonymous defaults to read so repositories without
+// one stay public.
+func NewPolicy(anonymous string, roles map[string]string) (Policy, error) {
+	policy := Policy{Anonymous: RoleRead, Roles: make(map[string]Role, len(roles))}
+
+	if anonymous != "" {
+		role, err := ParseRole(anonymous)
+		if err != nil {
+			return Policy{}, fmt.Errorf("invalid anonymous role: %w", err)
+		}
+
+		policy.Anonymous = role
+	}
+
+	for name, roleName := range roles {
+		role, err := ParseRole(roleName)
+		if err != nil {
+			return Policy{}, fmt.Errorf("invalid role for %s: %w", name, err)
+		}
+
+		policy.Roles[name] = role
+	}
+
+	return policy, nil
+}
+
+// RoleFor is the highest role the identity holds on repository; a nil identity is anonymous. Roles
+// configured for AnyUser or by name only apply to identities that are not federated.
+func (p Policy) RoleFor(repository string, identity *Identity) Role {
+	role := p.Anonymous
+
+	if identity == nil {
+		return role
+	}
+
+	if identity.Federated {
+		return max(role, identity.Grants[repository])
+	}
+
+	for _, granted := range []Role{p.Roles[AnyUser], p.Roles[identity.Name], identity.Grants[repository]} {
+		role = max(role, granted)
+	}
+
+	return role
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/apierror"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+var ErrInvalidLimit = errors.New("invalid limit")
+
+type RepositorySummary struct {
+	Name    string `json:"name"`
+	Mode    string `json:"mode"`
+	Publish bool   `json:"publish"`
+}
+
+type RepositoryList struct {
+	Repositories []RepositorySummary `json:"repositories"`
+	Next         string              `json:"next,omitempty"`
+}
+
+type ModuleList struct {
+	Modules []string `json:"modules"`
+	Next    string   `json:"next,omitempty"`
+}
+
+type ArtifactList struct {
+	Module    string   `json:"module"`
+	Artifacts []string `json:"artifacts"`
+	Next      string   `json:"next,omitempty"`
+}
+
+// RepositoryController lists the configured repositories the caller may read.
+type RepositoryController struct {
+	repositories []RepositorySummary
+	readable     func(ctx *gin.Context, repository string) bool
+}
+
+func NewRepositoryController(repositories []RepositorySummary,
+	readable func(ctx *gin.Context, repository string) bool) *RepositoryController {
+	sorted := slices.Clone(repositories)
+	slices.SortFunc(sorted, func(a, b RepositorySummary) int { return strings.Compare(a.Name, b.Name) })
+
+	return &RepositoryController{repositories: sorted, readable: readable}
+}
+
+func (rc *RepositoryController) ListRepositories(ctx *gin.Context) {
+	page, err := parsePage(ctx)
+	if err != nil {
+		apierror.Abort(ctx, http.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+
+		return
+	}
+
+	list := RepositoryList{Repositories: []RepositorySummary{}, Next: ""}
+
+	for _, repository := range rc.repositories {
+		if repository.Name <= page.Cursor || !rc.readable(ctx, repository.Name) {
+			continue
+		}
+
+		if len(list.Repositories) == page.Limit {
+			list.Next = list.Repositories[page.Limit-1].Name
+
+			break
+		}
+
+		list.Repositories = append(list.Repositories, repository)
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+type DiscoveryController struct {
+	service services.DiscoveryService
+	logger  *logrus.Logger
+}
+
+func NewDiscoveryController(service services.DiscoveryService, logger *logrus.Logger) *DiscoveryController {
+	return &DiscoveryController{service: service, logger: logger}
+}
+
+func (dc *DiscoveryController) ListModules(ctx *gin.Context) {
+	page, err := parsePage(ctx)
+	if err != nil {
+		apierror.Abort(ctx, http.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+
+		return
+	}
+
+	listing, err := dc.service.ListModules(ctx.Request.Context(), page)
+	if err != nil {
+		respondError(ctx, dc.logger, err, "failed to list modules")
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ModuleList{Modules: listing.Names, Next: listing.Next})
+}
+
+func (dc *DiscoveryController) ListArtifacts(ctx *gin.Context) {
+	moduleName := ctx.Param("module")
+
+	page, err := parsePage(ctx)
+	if err != nil {
+		apierror.Abort(ctx, http.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+
+		return
+	}
+
+	listing, err := dc.service.ListArtifacts(ctx.Request.Context(), moduleName, page)
+	if err != nil {
+		respondError(ctx, dc.logger, err, "failed to list artifacts")
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ArtifactList{Module: moduleName, Artifacts: listing.Names, Next: listing.Next})
+}
+
+// parsePage reads the limit and cursor query parameters; the cursor is the next value of a previous page.
+func parsePage(ctx *gin.Context) (providers.Page, error) {
+	limit, err := parseLimit(ctx, DefaultPageLimit)
+	if err != nil {
+		return providers.Page{}, err
+	}
+
+	return providers.Page{Limit: limit, Cursor: ctx.Query("cursor")}, nil
+}
+
+func parseLimit(ctx *gin.Context, fallback int) (int, error) {
+	value := ctx.Query("limit")
+	if value == "" {
+		return fallback, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > MaxPageLimit {
+		return 0, fmt.Errorf("%w %q: must be between 1 and %d", ErrInvalidLimit, value, MaxPageLimit)
+	}
+
+	return limit, nil
+}
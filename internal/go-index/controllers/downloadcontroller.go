@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/sirupsen/logrus"
+)
+
+type DownloadController struct {
+	service services.DownloadService
+	logger  *logrus.Logger
+}
+
+func NewDownloadController(service services.DownloadService, logger *logrus.Logger) *DownloadController {
+	return &DownloadController{service: service, logger: logger}
+}
+
+func (dc *DownloadController) Download(ctx *gin.Context) {
+	moduleName := ctx.Param("module")
+	artifactName := ctx.Param("artifact")
+	version := ctx.Param("version")
+	filename := ctx.Param("file")
+
+	download, err := dc.service.Open(ctx.Request.Context(), moduleName, artifactName, version, filename)
+	if err != nil {
+		respondError(ctx, dc.logger, err, "failed to download")
+
+		return
+	}
+
+	if download.RedirectURL != "" {
+		ctx.Redirect(http.StatusTemporaryRedirect, download.RedirectURL)
+
+		return
+	}
+	defer download.Body.Close()
+
+	artifact := download.Artifact
+
+	ctx.Header("Content-Type", artifact.ContentType)
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": artifact.Name}))
+
+	if checksum := artifact.Checksums["sha256"]; checksum != "" {
+		ctx.Header("ETag", `"`+checksum+`"`)
+	} else if artifact.ETag != "" {
+		ctx.Header("ETag", `"`+artifact.ETag+`"`)
+	}
+
+	// Seekable bodies (local files) get Range and conditional request support.
+	if seeker, ok := download.Body.(io.ReadSeeker); ok {
+		http.ServeContent(ctx.Writer, ctx.Request, artifact.Name, artifact.LastModified, seeker)
+
+		return
+	}
+
+	if artifact.Size > 0 {
+		ctx.Header("Content-Length", strconv.FormatInt(artifact.Size, 10))
+	}
+
+	ctx.Status(http.StatusOK)
+
+	if _, err := io.Copy(ctx.Writer, download.Body); err != nil {
+		dc.logger.WithError(err).Errorf("Failed to stream %s", artifact.Path)
+	}
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/apierror"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+	"github.com/sirupsen/logrus"
+)
+
+// errorStatuses maps errors to responses; the first match wins, so more specific errors come first.
+// Errors not listed are logged and answered with 500.
+var errorStatuses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, apierror.CodeTimeout},
+	{services.ErrInvalidStoredVersion, http.StatusInternalServerError, apierror.CodeInternal},
+	{versioning.ErrInvalidConstraint, http.StatusBadRequest, apierror.CodeInvalidConstraint},
+	{versioning.ErrInvalidVersion, http.StatusBadRequest, apierror.CodeInvalidVersion},
+	{ErrMissingFilename, http.StatusBadRequest, apierror.CodeBadRequest},
+	{ErrInvalidUpload, http.StatusBadRequest, apierror.CodeBadRequest},
+	{ErrInvalidLimit, http.StatusBadRequest, apierror.CodeBadRequest},
+	{providers.ErrInvalidPath, http.StatusBadRequest, apierror.CodeBadRequest},
+	{providers.ErrLayoutMismatch, http.StatusBadRequest, apierror.CodeBadRequest},
+	{services.ErrInvalidModule, http.StatusBadRequest, apierror.CodeBadRequest},
+	{services.ErrModuleNotFound, http.StatusNotFound, apierror.CodeModuleNotFound},
+	{services.ErrArtifactNotFound, http.StatusNotFound, apierror.CodeArtifactNotFound},
+	{services.ErrVersionNotFound, http.StatusNotFound, apierror.CodeVersionNotFound},
+	{services.ErrNoMatchingVersion, http.StatusNotFound, apierror.CodeVersionNotFound},
+	{services.ErrFileNotFound, http.StatusNotFound, apierror.CodeFileNotFound},
+	{services.ErrAmbiguousDownload, http.StatusMultipleChoices, apierror.CodeAmbiguousDownload},
+	{providers.ErrFileExists, http.StatusConflict, apierror.CodeConflict},
+	{services.ErrVersionExists, http.StatusConflict, apierror.CodeConflict},
+	{services.ErrPublishUnsupported, http.StatusNotImplemented, apierror.CodeNotImplemented},
+	{services.ErrDeleteUnsupported, http.StatusNotImplemented, apierror.CodeNotImplemented},
+	{services.ErrListingUnsupported, http.StatusNotImplemented, apierror.CodeNotImplemented},
+	{services.ErrProviderUnavailable, http.StatusBadGateway, apierror.CodeProviderUnavailable},
+}
+
+// serverMessages replace the error text of 5xx responses, which may hold file system paths, bucket names
+// or storage errors; the request ID ties the response to the logged error.
+var serverMessages = map[string]string{
+	apierror.CodeTimeout:             "the request timed out",
+	apierror.CodeProviderUnavailable: "the storage provider is unavailable",
+	apierror.CodeNotImplemented:      "the operation is not supported by this repository",
+	apierror.CodeInternal:            "internal server error",
+}
+
+// statusClientClosedRequest is logged for requests whose client went away before the response.
+const statusClientClosedRequest = 499
+
+// respondError answers with the status and code of err. Server errors are logged with message, e.g.
+// "failed to get versions", and answered without the error text.
+func respondError(ctx *gin.Context, logger *logrus.Logger, err error, message string) {
+	if clientWentAway(ctx, logger, err, message) {
+		return
+	}
+
+	status, code := errorStatus(err)
+	if status < http.StatusInternalServerError {
+		apierror.Abort(ctx, status, code, err.Error())
+
+		return
+	}
+
+	logError(ctx, logger, err, message)
+	apierror.Abort(ctx, status, code, serverMessages[code])
+}
+
+// clientWentAway answers with statusClientClosedRequest when err comes from the request being cancelled.
+func clientWentAway(ctx *gin.Context, logger *logrus.Logger, err error, message string) bool {
+	if !errors.Is(err, context.Canceled) || ctx.Request.Context().Err() == nil {
+		return false
+	}
+
+	logger.WithError(err).WithField("requestId", apierror.RequestIDFrom(ctx)).Debugf("%s: client went away", message)
+	ctx.AbortWithStatus(statusClientClosedRequest)
+
+	return true
+}
+
+func errorStatus(err error) (int, string) {
+	for _, mapping := range errorStatuses {
+		if errors.Is(err, mapping.err) {
+			return mapping.status, mapping.code
+		}
+	}
+
+	return http.StatusInternalServerError, apierror.CodeInternal
+}
+
+func logError(ctx *gin.Context, logger *logrus.Logger, err error, message string) {
+	logger.WithError(err).WithFields(logrus.Fields{
+		"path":      ctx.Request.URL.Path,
+		"requestId": apierror.RequestIDFrom(ctx),
+	}).Error(message)
+}
+
+// NotFound answers requests that match no route.
+func NotFound(ctx *gin.Context) {
+	apierror.Abort(ctx, http.StatusNotFound, apierror.CodeNotFound, "no route for "+ctx.Request.URL.Path)
+}
@@ -0,0 +1,150 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/apierror"
+	"github.com/mauhlik/go-index/internal/go-index/controllers"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+	"github.com/sirupsen/logrus"
+)
+
+var errDiskFailed = errors.New("disk failed")
+
+// unavailableProvider fails every listing of the artifact broken, and reports the listings of cancelled
+// as cancelled.
+type unavailableProvider struct {
+	providers.Provider
+}
+
+func (p unavailableProvider) GetVersions(ctx context.Context, moduleName, artifactName string) ([]string, error) {
+	switch artifactName {
+	case "broken":
+		return nil, errors.Join(providers.ErrProviderUnavailable, errDiskFailed)
+	case "cancelled":
+		return nil, context.Canceled
+	}
+
+	return p.Provider.GetVersions(ctx, moduleName, artifactName) //nolint:wrapcheck
+}
+
+func TestVersionControllerErrors(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+
+	for _, name := range []string{"fe/app1/app1-1.0.0.txt", "fe/nightly/nightly-latest.txt"} {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte("content"), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	provider := unavailableProvider{Provider: providers.NewLocalProvider(dir, nil)}
+	service := services.NewService(provider, versioning.SemverScheme{}, services.InvalidVersionSkip, logrus.New())
+	controller := controllers.NewVersionController(service, logrus.New())
+
+	router := gin.New()
+	router.Use(apierror.RequestID())
+	router.NoRoute(controllers.NotFound)
+	router.GET("/:module/:artifact/versions", controller.GetVersions)
+	router.GET("/:module/:artifact/versions/latest", controller.GetLatestVersion)
+	router.GET("/:module/:artifact/versions/resolve", controller.ResolveVersion)
+	router.GET("/:module/:artifact/versions/:version", controller.GetVersionDetails)
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{"/fe/missing/versions", http.StatusNotFound, apierror.CodeArtifactNotFound},
+		{"/fe/missing/versions/latest", http.StatusNotFound, apierror.CodeArtifactNotFound},
+		{"/fe/nightly/versions/latest", http.StatusNotFound, apierror.CodeVersionNotFound},
+		{"/fe/app1/versions/9.9.9", http.StatusNotFound, apierror.CodeVersionNotFound},
+		{"/fe/app1/versions/resolve?constraint=%5E2.0.0", http.StatusNotFound, apierror.CodeVersionNotFound},
+		{"/fe/app1/versions/resolve?constraint=not+a+constraint", http.StatusBadRequest,
+			apierror.CodeInvalidConstraint},
+		{"/fe/app1/versions/resolve", http.StatusBadRequest, apierror.CodeBadRequest},
+		{"/fe/broken/versions/latest", http.StatusBadGateway, apierror.CodeProviderUnavailable},
+		{"/unknown", http.StatusNotFound, apierror.CodeNotFound},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, test.path, nil)
+		request.Header.Set(apierror.RequestIDHeader, "req-1")
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		var body apierror.Response
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET %s returned an invalid error body %q: %v", test.path, recorder.Body.String(), err)
+		}
+
+		if recorder.Code != test.status || body.Code != test.code || body.Message == "" || body.RequestID != "req-1" {
+			t.Errorf("GET %s returned %d %+v; want %d with code %s and request ID req-1",
+				test.path, recorder.Code, body, test.status, test.code)
+		}
+
+		if header := recorder.Header().Get(apierror.RequestIDHeader); header != "req-1" {
+			t.Errorf("GET %s returned request ID header %q; want req-1", test.path, header)
+		}
+
+		if strings.Contains(body.Message, errDiskFailed.Error()) {
+			t.Errorf("GET %s returned message %q; want the storage error kept out of the response", test.path,
+				body.Message)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequestWithContext(ctx, http.MethodGet, "/fe/cancelled/versions", nil))
+
+	if recorder.Code != 499 {
+		t.Errorf("GET of a cancelled request returned %d; want 499", recorder.Code)
+	}
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(apierror.RequestID())
+	router.NoRoute(controllers.NotFound)
+
+	request := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	request.Header.Set(apierror.RequestIDHeader, "not valid\n")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	var body apierror.Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET /unknown returned an invalid error body %q: %v", recorder.Body.String(), err)
+	}
+
+	header := recorder.Header().Get(apierror.RequestIDHeader)
+	if len(header) != 32 || body.RequestID != header {
+		t.Errorf("GET /unknown returned request ID %q in the header and %q in the body; want the same generated ID",
+			header, body.RequestID)
+	}
+}
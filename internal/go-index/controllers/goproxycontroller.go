@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/sirupsen/logrus"
+)
+
+type GoProxyController struct {
+	service services.GoProxyService
+	logger  *logrus.Logger
+}
+
+func NewGoProxyController(service services.GoProxyService, logger *logrus.Logger) *GoProxyController {
+	return &GoProxyController{service: service, logger: logger}
+}
+
+func (gc *GoProxyController) Handle(ctx *gin.Context) {
+	path := strings.TrimPrefix(ctx.Param("path"), "/")
+
+	if modulePath, ok := strings.CutSuffix(path, "/@latest"); ok {
+		gc.getLatest(ctx, modulePath)
+
+		return
+	}
+
+	modulePath, file, ok := strings.Cut(path, "/@v/")
+	if !ok {
+		ctx.String(http.StatusNotFound, "not found")
+
+		return
+	}
+
+	switch {
+	case file == "list":
+		gc.getList(ctx, modulePath)
+	case strings.HasSuffix(file, ".info"):
+		gc.getInfo(ctx, modulePath, strings.TrimSuffix(file, ".info"))
+	case strings.HasSuffix(file, ".mod"):
+		gc.getFile(ctx, modulePath, strings.TrimSuffix(file, ".mod"), gc.service.Mod, "text/plain; charset=utf-8")
+	case strings.HasSuffix(file, ".zip"):
+		gc.getFile(ctx, modulePath, strings.TrimSuffix(file, ".zip"), gc.service.Zip, "application/zip")
+	default:
+		ctx.String(http.StatusNotFound, "not found")
+	}
+}
+
+func (gc *GoProxyController) getList(ctx *gin.Context, modulePath string) {
+	versions, err := gc.service.List(ctx.Request.Context(), modulePath)
+	if err != nil {
+		gc.respondError(ctx, err)
+
+		return
+	}
+
+	body := strings.Join(versions, "\n")
+	if body != "" {
+		body += "\n"
+	}
+
+	ctx.String(http.StatusOK, body)
+}
+
+func (gc *GoProxyController) getLatest(ctx *gin.Context, modulePath string) {
+	info, err := gc.service.Latest(ctx.Request.Context(), modulePath)
+	if err != nil {
+		gc.respondError(ctx, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
+
+func (gc *GoProxyController) getInfo(ctx *gin.Context, modulePath, version string) {
+	info, err := gc.service.Info(ctx.Request.Context(), modulePath, version)
+	if err != nil {
+		gc.respondError(ctx, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
+
+func (gc *GoProxyController) getFile(ctx *gin.Context, modulePath, version string,
+	open func(ctx context.Context, modulePath, version string) (io.ReadCloser, error), contentType string) {
+	body, err := open(ctx.Request.Context(), modulePath, version)
+	if err != nil {
+		gc.respondError(ctx, err)
+
+		return
+	}
+	defer body.Close()
+
+	ctx.Header("Content-Type", contentType)
+	ctx.Status(http.StatusOK)
+
+	if _, err := io.Copy(ctx.Writer, body); err != nil {
+		gc.logger.WithError(err).Errorf("Failed to stream file for %s@%s", modulePath, version)
+	}
+}
+
+// respondError answers in plain text as the go command expects, with the statuses and server messages of
+// the other controllers.
+func (gc *GoProxyController) respondError(ctx *gin.Context, err error) {
+	const message = "failed to serve Go module proxy request"
+
+	if clientWentAway(ctx, gc.logger, err, message) {
+		return
+	}
+
+	status, code := errorStatus(err)
+	if status < http.StatusInternalServerError {
+		ctx.String(status, err.Error())
+
+		return
+	}
+
+	logError(ctx, gc.logger, err, message)
+	ctx.String(status, serverMessages[code])
+}
@@ -0,0 +1,93 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/controllers"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/sirupsen/logrus"
+)
+
+// failingGoProxyService fails every call with the error registered for the module path.
+type failingGoProxyService struct {
+	errs map[string]error
+}
+
+func (s failingGoProxyService) List(_ context.Context, modulePath string) ([]string, error) {
+	return nil, s.errs[modulePath]
+}
+
+func (s failingGoProxyService) Latest(_ context.Context, modulePath string) (*services.ModuleInfo, error) {
+	return nil, s.errs[modulePath]
+}
+
+func (s failingGoProxyService) Info(_ context.Context, modulePath, _ string) (*services.ModuleInfo, error) {
+	return nil, s.errs[modulePath]
+}
+
+func (s failingGoProxyService) Mod(_ context.Context, modulePath, _ string) (io.ReadCloser, error) {
+	return nil, s.errs[modulePath]
+}
+
+func (s failingGoProxyService) Zip(_ context.Context, modulePath, _ string) (io.ReadCloser, error) {
+	return nil, s.errs[modulePath]
+}
+
+func TestGoProxyControllerErrors(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	service := failingGoProxyService{errs: map[string]error{
+		"example.com/missing":   fmt.Errorf("%w: example.com/missing", services.ErrModuleNotFound),
+		"example.com/invalid":   fmt.Errorf("%w: example.com/invalid", services.ErrInvalidModule),
+		"example.com/slow":      context.DeadlineExceeded,
+		"example.com/storage":   fmt.Errorf("read /srv/modules/example.com: %w", providers.ErrProviderUnavailable),
+		"example.com/internal":  errors.New("open /srv/modules/example.com/internal: permission denied"),
+		"example.com/cancelled": context.Canceled,
+	}}
+
+	router := gin.New()
+	router.GET("/go/*path", controllers.NewGoProxyController(service, logrus.New()).Handle)
+
+	testCases := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/go/example.com/missing/@v/list", http.StatusNotFound, "module not found: example.com/missing"},
+		{"/go/example.com/invalid/@latest", http.StatusBadRequest, "invalid module path: example.com/invalid"},
+		{"/go/example.com/slow/@v/v1.0.0.info", http.StatusGatewayTimeout, "the request timed out"},
+		{"/go/example.com/storage/@v/v1.0.0.mod", http.StatusBadGateway, "the storage provider is unavailable"},
+		{"/go/example.com/internal/@v/v1.0.0.zip", http.StatusInternalServerError, "internal server error"},
+	}
+
+	for _, testCase := range testCases {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, testCase.path, nil))
+
+		if recorder.Code != testCase.status || recorder.Body.String() != testCase.body ||
+			!strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain") {
+			t.Errorf("GET %s returned %d %q of type %s; want %d %q in plain text", testCase.path, recorder.Code,
+				recorder.Body.String(), recorder.Header().Get("Content-Type"), testCase.status, testCase.body)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequestWithContext(ctx, http.MethodGet, "/go/example.com/cancelled/@v/list", nil))
+
+	if recorder.Code != 499 {
+		t.Errorf("GET of a cancelled request returned %d; want 499", recorder.Code)
+	}
+}
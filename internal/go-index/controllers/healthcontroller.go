@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultCheckTimeout = 5 * time.Second
+
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// CheckResult is all an unauthenticated caller learns of a check; provider errors can name buckets, paths
+// and credentials, so they are only logged.
+type CheckResult struct {
+	Status string `json:"status"`
+}
+
+type Readiness struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type HealthController struct {
+	checks  []HealthCheck
+	timeout time.Duration
+	logger  *logrus.Logger
+}
+
+func NewHealthController(checks []HealthCheck, timeout time.Duration, logger *logrus.Logger) *HealthController {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+
+	return &HealthController{checks: checks, timeout: timeout, logger: logger}
+}
+
+// Healthz only reports that the process is serving requests.
+func (hc *HealthController) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": statusOK})
+}
+
+// Readyz runs every provider check in parallel, each bounded by the configured timeout.
+func (hc *HealthController) Readyz(ctx *gin.Context) {
+	readiness := Readiness{Status: statusOK, Checks: make(map[string]CheckResult, len(hc.checks))}
+
+	var (
+		mutex     sync.Mutex
+		waitGroup sync.WaitGroup
+	)
+
+	for _, check := range hc.checks {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			result := hc.run(ctx.Request.Context(), check)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			readiness.Checks[check.Name] = result
+			if result.Status != statusOK {
+				readiness.Status = statusUnavailable
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	if readiness.Status != statusOK {
+		ctx.JSON(http.StatusServiceUnavailable, readiness)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, readiness)
+}
+
+func (hc *HealthController) run(parent context.Context, check HealthCheck) CheckResult {
+	checkCtx, cancel := context.WithTimeout(parent, hc.timeout)
+	defer cancel()
+
+	started := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		done <- check.Check(checkCtx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-checkCtx.Done():
+		err = fmt.Errorf("check timed out after %s: %w", hc.timeout, checkCtx.Err())
+	}
+
+	if err != nil {
+		hc.logger.WithError(err).WithField("duration", time.Since(started).Round(time.Millisecond).String()).
+			Warnf("Readiness check %s failed", check.Name)
+
+		return CheckResult{Status: statusUnavailable}
+	}
+
+	return CheckResult{Status: statusOK}
+}
@@ -0,0 +1,79 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/controllers"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHealthControllerReadyz(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	healthy := controllers.HealthCheck{Name: "local", Check: func(context.Context) error { return nil }}
+	failing := controllers.HealthCheck{Name: "s3", Check: func(context.Context) error {
+		return errors.New("access to bucket internal-artifacts forbidden")
+	}}
+	hanging := controllers.HealthCheck{Name: "slow", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Second)
+
+		return nil
+	}}
+
+	testCases := []struct {
+		name   string
+		checks []controllers.HealthCheck
+		status int
+		failed []string
+	}{
+		{"healthy", []controllers.HealthCheck{healthy}, http.StatusOK, nil},
+		{"failing", []controllers.HealthCheck{healthy, failing}, http.StatusServiceUnavailable, []string{"s3"}},
+		{"timeout", []controllers.HealthCheck{healthy, hanging}, http.StatusServiceUnavailable, []string{"slow"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			controller := controllers.NewHealthController(testCase.checks, 50*time.Millisecond, logrus.New())
+			router := gin.New()
+			router.GET("/readyz", controller.Readyz)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if recorder.Code != testCase.status {
+				t.Fatalf("Readyz returned %d; want %d", recorder.Code, testCase.status)
+			}
+
+			var readiness controllers.Readiness
+			if err := json.Unmarshal(recorder.Body.Bytes(), &readiness); err != nil {
+				t.Fatalf("Failed to decode readiness: %v", err)
+			}
+
+			if len(readiness.Checks) != len(testCase.checks) {
+				t.Errorf("Readyz reported %d checks; want %d", len(readiness.Checks), len(testCase.checks))
+			}
+
+			for _, name := range testCase.failed {
+				if result := readiness.Checks[name]; result.Status != "unavailable" {
+					t.Errorf("Check %s reported %+v; want a failure", name, result)
+				}
+			}
+
+			if body := recorder.Body.String(); strings.Contains(body, "internal-artifacts") {
+				t.Errorf("Readyz returned %s; want the check errors left out", body)
+			}
+		})
+	}
+}
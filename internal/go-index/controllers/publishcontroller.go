@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/sirupsen/logrus"
+)
+
+const publishFormField = "file"
+
+var (
+	ErrMissingFilename = errors.New("missing file name, use the file query parameter or a Content-Disposition header")
+	// ErrInvalidUpload marks request bodies that cannot be read, such as malformed or truncated multipart forms.
+	ErrInvalidUpload = errors.New("invalid upload")
+)
+
+type PublishController struct {
+	service services.PublishService
+	logger  *logrus.Logger
+}
+
+func NewPublishController(service services.PublishService, logger *logrus.Logger) *PublishController {
+	return &PublishController{service: service, logger: logger}
+}
+
+// Publish accepts either a multipart/form-data body with one or more "file" parts, or a raw body
+// named by the file query parameter or the Content-Disposition header. All files of the request are
+// published as one release of the version.
+func (pc *PublishController) Publish(ctx *gin.Context) {
+	moduleName := ctx.Param("module")
+	artifactName := ctx.Param("artifact")
+	version := ctx.Param("version")
+
+	var (
+		uploads services.Uploads
+		err     error
+	)
+
+	if strings.HasPrefix(ctx.ContentType(), "multipart/form-data") {
+		uploads, err = multipartUploads(ctx)
+	} else {
+		uploads, err = bodyUploads(ctx)
+	}
+
+	if err != nil {
+		respondError(ctx, pc.logger, err, "failed to publish")
+
+		return
+	}
+
+	files, err := pc.service.Publish(ctx.Request.Context(), moduleName, artifactName, version, uploads)
+	if err == nil && len(files) == 0 {
+		err = ErrMissingFilename
+	}
+
+	if err != nil {
+		respondError(ctx, pc.logger, err, "failed to publish")
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, services.VersionDetails{
+		Module:   moduleName,
+		Artifact: artifactName,
+		Version:  version,
+		Files:    files,
+	})
+}
+
+// DeleteVersion removes every file of the version.
+func (pc *PublishController) DeleteVersion(ctx *gin.Context) {
+	err := pc.service.DeleteVersion(ctx.Request.Context(), ctx.Param("module"), ctx.Param("artifact"),
+		ctx.Param("version"))
+	if err != nil {
+		respondError(ctx, pc.logger, err, "failed to delete version")
+
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func bodyUploads(ctx *gin.Context) (services.Uploads, error) {
+	filename := ctx.Query("file")
+	if filename == "" {
+		if _, params, err := mime.ParseMediaType(ctx.GetHeader("Content-Disposition")); err == nil {
+			filename = params["filename"]
+		}
+	}
+
+	if filename == "" {
+		return nil, ErrMissingFilename
+	}
+
+	done := false
+
+	return func() (services.Upload, error) {
+		if done {
+			return services.Upload{}, io.EOF
+		}
+
+		done = true
+
+		return services.Upload{Filename: filename, Body: uploadBody{reader: ctx.Request.Body}}, nil
+	}, nil
+}
+
+// multipartUploads streams parts straight to the provider instead of buffering the form.
+func multipartUploads(ctx *gin.Context) (services.Uploads, error) {
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read multipart body: %w", ErrInvalidUpload, err)
+	}
+
+	return func() (services.Upload, error) {
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				return services.Upload{}, io.EOF
+			}
+
+			if err != nil {
+				return services.Upload{}, fmt.Errorf("%w: failed to read multipart body: %w", ErrInvalidUpload, err)
+			}
+
+			if part.FormName() == publishFormField && part.FileName() != "" {
+				return services.Upload{Filename: part.FileName(), Body: uploadBody{reader: part}}, nil
+			}
+		}
+	}, nil
+}
+
+// uploadBody marks read errors as ErrInvalidUpload, so a body cut off while a provider stores it is not
+// mistaken for a failing provider.
+type uploadBody struct {
+	reader io.Reader
+}
+
+func (b uploadBody) Read(data []byte) (int, error) {
+	n, err := b.reader.Read(data)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, fmt.Errorf("%w: failed to read body: %w", ErrInvalidUpload, err)
+	}
+
+	return n, err //nolint:wrapcheck
+}
@@ -0,0 +1,66 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/apierror"
+	"github.com/mauhlik/go-index/internal/go-index/controllers"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+	"github.com/sirupsen/logrus"
+)
+
+func TestPublishControllerInvalidUploads(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	provider := providers.NewLocalProvider(t.TempDir(), nil)
+	service := services.NewPublishService(provider, versioning.SemverScheme{}, logrus.New())
+
+	router := gin.New()
+	router.PUT("/:module/:artifact/versions/:version", controllers.NewPublishController(service, logrus.New()).Publish)
+
+	const part = "--boundary\r\nContent-Disposition: form-data; name=\"file\"; filename=\"app1-1.0.0.txt\"\r\n\r\n"
+
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"missing boundary", "multipart/form-data", part + "content\r\n--boundary--\r\n", http.StatusBadRequest},
+		{"truncated part", "multipart/form-data; boundary=boundary", part + "cont", http.StatusBadRequest},
+		{"malformed part", "multipart/form-data; boundary=boundary", "--boundary\r\nnot a header\r\n\r\n",
+			http.StatusBadRequest},
+		{"complete", "multipart/form-data; boundary=boundary", part + "content\r\n--boundary--\r\n", http.StatusCreated},
+	}
+
+	for _, testCase := range testCases {
+		request := httptest.NewRequest(http.MethodPut, "/fe/app1/versions/1.0.0", strings.NewReader(testCase.body))
+		request.Header.Set("Content-Type", testCase.contentType)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != testCase.status {
+			t.Errorf("Publish of a %s body returned %d %s; want %d", testCase.name, recorder.Code,
+				recorder.Body.String(), testCase.status)
+		}
+
+		if testCase.status == http.StatusBadRequest && !strings.Contains(recorder.Body.String(), apierror.CodeBadRequest) {
+			t.Errorf("Publish of a %s body returned %s; want code %s", testCase.name, recorder.Body.String(),
+				apierror.CodeBadRequest)
+		}
+	}
+
+	if _, err := provider.Open(context.Background(), "fe/app1/app1-1.0.0.txt"); errors.Is(err, providers.ErrFileNotFound) {
+		t.Error("The complete upload was rejected after the invalid ones")
+	}
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/apierror"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultSearchLimit = 50
+
+	// searchRetryAfter is suggested to clients while the first index is still being built.
+	searchRetryAfter = 5 * time.Second
+)
+
+type SearchResponse struct {
+	Query     string                  `json:"query"`
+	Results   []services.SearchResult `json:"results"`
+	IndexedAt time.Time               `json:"indexedAt"`
+}
+
+type SearchController struct {
+	service  services.SearchService
+	readable func(ctx *gin.Context, repository string) bool
+	logger   *logrus.Logger
+}
+
+func NewSearchController(service services.SearchService, readable func(ctx *gin.Context, repository string) bool,
+	logger *logrus.Logger) *SearchController {
+	return &SearchController{service: service, readable: readable, logger: logger}
+}
+
+// Search answers from the index only, so results may lag behind storage by up to the refresh interval.
+func (sc *SearchController) Search(ctx *gin.Context) {
+	query := strings.TrimSpace(ctx.Query("q"))
+	if query == "" {
+		apierror.Abort(ctx, http.StatusBadRequest, apierror.CodeBadRequest, "missing q query parameter")
+
+		return
+	}
+
+	limit, err := parseLimit(ctx, DefaultSearchLimit)
+	if err != nil {
+		apierror.Abort(ctx, http.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+
+		return
+	}
+
+	results, indexedAt := sc.service.Search(query, limit, func(repository string) bool {
+		return sc.readable(ctx, repository)
+	})
+
+	if indexedAt.IsZero() {
+		ctx.Header("Retry-After", strconv.Itoa(int(searchRetryAfter.Seconds())))
+		apierror.Abort(ctx, http.StatusServiceUnavailable, apierror.CodeUnavailable, "search index is being built")
+
+		return
+	}
+
+	sc.logger.Debugf("Search for %q returned %d results", query, len(results))
+	ctx.JSON(http.StatusOK, SearchResponse{Query: query, Results: results, IndexedAt: indexedAt.UTC()})
+}
@@ -0,0 +1,46 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/controllers"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+	"github.com/sirupsen/logrus"
+)
+
+type hangingProvider struct {
+	providers.Provider
+}
+
+func (hangingProvider) GetVersions(ctx context.Context, _, _ string) ([]string, error) {
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+func TestRequestTimeout(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	service := services.NewService(hangingProvider{}, versioning.SemverScheme{}, services.InvalidVersionSkip,
+		logrus.New())
+	controller := controllers.NewVersionController(service, logrus.New())
+
+	router := gin.New()
+	router.Use(controllers.RequestTimeout(20 * time.Millisecond))
+	router.GET("/:module/:artifact/versions/latest", controller.GetLatestVersion)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/fe/app1/versions/latest", nil))
+
+	if recorder.Code != http.StatusGatewayTimeout {
+		t.Errorf("GetLatestVersion returned %d; want %d", recorder.Code, http.StatusGatewayTimeout)
+	}
+}
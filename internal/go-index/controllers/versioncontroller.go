@@ -1,14 +1,17 @@
 package controllers
 
 import (
-	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/apierror"
 	"github.com/mauhlik/go-index/internal/go-index/services"
 	"github.com/sirupsen/logrus"
 )
 
+const SkippedVersionsHeader = "X-Skipped-Versions"
+
 type VersionController struct {
 	service services.VersionService
 	logger  *logrus.Logger
@@ -24,12 +27,9 @@ func (vc *VersionController) GetVersions(ctx *gin.Context) {
 
 	vc.logger.Infof("Fetching versions for module: %s, artifact: %s", moduleName, artifactName)
 
-	versions, err := vc.service.GetVersions(moduleName, artifactName)
+	versions, err := vc.service.GetVersions(ctx.Request.Context(), moduleName, artifactName)
 	if err != nil {
-		vc.logger.WithError(err).Errorf("Failed to get versions for %s/%s", moduleName, artifactName)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("failed to get versions: %v", err),
-		})
+		respondError(ctx, vc.logger, err, "failed to get versions")
 
 		return
 	}
@@ -37,21 +37,66 @@ func (vc *VersionController) GetVersions(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, versions)
 }
 
+func (vc *VersionController) GetVersionDetails(ctx *gin.Context) {
+	moduleName := ctx.Param("module")
+	artifactName := ctx.Param("artifact")
+	version := ctx.Param("version")
+
+	vc.logger.Infof("Fetching details for module: %s, artifact: %s, version: %s", moduleName, artifactName, version)
+
+	details, err := vc.service.GetVersionDetails(ctx.Request.Context(), moduleName, artifactName, version)
+	if err != nil {
+		respondError(ctx, vc.logger, err, "failed to get version details")
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, details)
+}
+
 func (vc *VersionController) GetLatestVersion(ctx *gin.Context) {
 	moduleName := ctx.Param("module")
 	artifactName := ctx.Param("artifact")
 
 	vc.logger.Infof("Fetching latest version for module: %s, artifact: %s", moduleName, artifactName)
 
-	latestVersion, err := vc.service.GetLatestVersion(moduleName, artifactName)
+	resolution, err := vc.service.GetLatestVersion(ctx.Request.Context(), moduleName, artifactName)
+	if err != nil {
+		respondError(ctx, vc.logger, err, "failed to get latest version")
+
+		return
+	}
+
+	setSkippedHeader(ctx, resolution.Skipped)
+	ctx.JSON(http.StatusOK, resolution.Version)
+}
+
+func (vc *VersionController) ResolveVersion(ctx *gin.Context) {
+	moduleName := ctx.Param("module")
+	artifactName := ctx.Param("artifact")
+	constraint := ctx.Query("constraint")
+
+	vc.logger.Infof("Resolving version for module: %s, artifact: %s, constraint: %s", moduleName, artifactName, constraint)
+
+	if constraint == "" {
+		apierror.Abort(ctx, http.StatusBadRequest, apierror.CodeBadRequest, "missing constraint query parameter")
+
+		return
+	}
+
+	resolution, err := vc.service.ResolveVersion(ctx.Request.Context(), moduleName, artifactName, constraint)
 	if err != nil {
-		vc.logger.WithError(err).Errorf("Failed to get latest version for %s/%s", moduleName, artifactName)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("failed to get latest version: %v", err),
-		})
+		respondError(ctx, vc.logger, err, "failed to resolve version")
 
 		return
 	}
 
-	ctx.JSON(http.StatusOK, latestVersion)
+	setSkippedHeader(ctx, resolution.Skipped)
+	ctx.JSON(http.StatusOK, resolution.Version)
+}
+
+func setSkippedHeader(ctx *gin.Context, skipped []string) {
+	if len(skipped) > 0 {
+		ctx.Header(SkippedVersionsHeader, strings.Join(skipped, ","))
+	}
 }
@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics are the application metrics served on /metrics. Each instance has its own registry, so
+// handlers built in tests or on reload do not collide.
+type Metrics struct {
+	handler         http.Handler
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	providerCalls   *prometheus.HistogramVec
+	providerErrors  *prometheus.CounterVec
+	skippedVersions *prometheus.CounterVec
+	cacheRequests   *cacheCollector
+}
+
+func New() *Metrics {
+	registry := prometheus.NewRegistry()
+
+	metrics := &Metrics{
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), //nolint:exhaustruct
+		requests: newCounter(registry, "go_index_http_requests_total",
+			"HTTP requests by repository, route, method and status.", "repository", "route", "method", "status"),
+		requestDuration: newHistogram(registry, "go_index_http_request_duration_seconds",
+			"HTTP request latency by repository, route and method.", "repository", "route", "method"),
+		providerCalls: newHistogram(registry, "go_index_provider_call_duration_seconds",
+			"Backend call latency such as one S3 ListObjectsV2 page or a local ReadDir.", "repository", "operation"),
+		providerErrors: newCounter(registry, "go_index_provider_call_errors_total",
+			"Failed backend calls, not counting missing files.", "repository", "operation"),
+		skippedVersions: newCounter(registry, "go_index_skipped_versions_total",
+			"Versions skipped because they did not parse in the repository's scheme, counted each time a "+
+				"listing is parsed.", "repository"),
+		cacheRequests: newCacheCollector(),
+	}
+
+	registry.MustRegister(
+		metrics.cacheRequests,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}), //nolint:exhaustruct
+	)
+
+	return metrics
+}
+
+func newCounter(registry *prometheus.Registry, name, help string, labels ...string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels) //nolint:exhaustruct
+	registry.MustRegister(counter)
+
+	return counter
+}
+
+func newHistogram(registry *prometheus.Registry, name, help string, labels ...string) *prometheus.HistogramVec {
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{ //nolint:exhaustruct
+		Name:    name,
+		Help:    help,
+		Buckets: prometheus.DefBuckets,
+	}, labels)
+	registry.MustRegister(histogram)
+
+	return histogram
+}
+
+// Middleware records every request of a repository; unmatched routes are grouped to bound cardinality.
+func (m *Metrics) Middleware(repository string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		started := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		method := ctx.Request.Method
+		m.requests.WithLabelValues(repository, route, method, strconv.Itoa(ctx.Writer.Status())).Inc()
+		m.requestDuration.WithLabelValues(repository, route, method).Observe(time.Since(started).Seconds())
+	}
+}
+
+func (m *Metrics) ProviderObserver(repository string) providers.CallObserver {
+	return func(operation string, duration time.Duration, err error) {
+		m.providerCalls.WithLabelValues(repository, operation).Observe(duration.Seconds())
+
+		if isProviderError(err) {
+			m.providerErrors.WithLabelValues(repository, operation).Inc()
+		}
+	}
+}
+
+// isProviderError leaves out missing files, which are looked up as a matter of course, and calls the
+// caller gave up on, so the error count only grows when the backend fails.
+func isProviderError(err error) bool {
+	return err != nil && !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, providers.ErrFileNotFound) &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func (m *Metrics) SkipObserver(repository string) services.SkipObserver {
+	return func(count int) {
+		m.skippedVersions.WithLabelValues(repository).Add(float64(count))
+	}
+}
+
+// RegisterCache reports the cache statistics of repository, replacing the cache registered before it.
+func (m *Metrics) RegisterCache(repository string, stats func() providers.CacheStats) {
+	m.cacheRequests.setSource(repository, stats)
+}
+
+func (m *Metrics) UnregisterCache(repository string) {
+	m.cacheRequests.removeSource(repository)
+}
+
+func (m *Metrics) Handler(ctx *gin.Context) {
+	m.handler.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+// cacheCollector reports the counters the caches keep themselves, read at scrape time.
+type cacheCollector struct {
+	desc *prometheus.Desc
+
+	mutex   sync.Mutex
+	sources map[string]func() providers.CacheStats
+}
+
+func newCacheCollector() *cacheCollector {
+	return &cacheCollector{
+		desc: prometheus.NewDesc("go_index_cache_requests_total",
+			"Cached listing lookups by result: hit, stale, miss or coalesced.", []string{"repository", "result"}, nil),
+		mutex:   sync.Mutex{},
+		sources: map[string]func() providers.CacheStats{},
+	}
+}
+
+func (c *cacheCollector) setSource(repository string, stats func() providers.CacheStats) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.sources[repository] = stats
+}
+
+func (c *cacheCollector) removeSource(repository string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.sources, repository)
+}
+
+func (c *cacheCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.desc
+}
+
+func (c *cacheCollector) Collect(metrics chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for repository, stats := range c.sources {
+		current := stats()
+
+		for _, sample := range []struct {
+			result string
+			value  uint64
+		}{
+			{"hit", current.Hits},
+			{"stale", current.StaleHits},
+			{"miss", current.Misses},
+			{"coalesced", current.Coalesced},
+		} {
+			metrics <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, float64(sample.value),
+				repository, sample.result)
+		}
+	}
+}
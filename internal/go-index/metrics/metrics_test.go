@@ -0,0 +1,109 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/mauhlik/go-index/internal/go-index/metrics"
+	"github.com/mauhlik/go-index/internal/go-index/mocks"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	appMetrics := metrics.New()
+	router := gin.New()
+	router.GET("/metrics", appMetrics.Handler)
+
+	group := router.Group("/api/files")
+	group.Use(appMetrics.Middleware("files"))
+	group.GET("/:module/:artifact/versions/latest", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, "1.0.0")
+	})
+
+	appMetrics.SkipObserver("files")(2)
+
+	observer := appMetrics.ProviderObserver("files")
+	observer("ListObjectsV2", 20*time.Millisecond, nil)
+	observer("ListObjectsV2", 20*time.Millisecond, errors.New("access denied"))
+	observer("GetObject", time.Millisecond, providers.ErrFileNotFound)
+
+	appMetrics.RegisterCache("files", func() providers.CacheStats {
+		return providers.CacheStats{Hits: 3, StaleHits: 0, Misses: 1, Coalesced: 0}
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/files/fe/app1/versions/latest", nil))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := recorder.Body.String()
+
+	for _, line := range []string{
+		`go_index_http_requests_total{method="GET",repository="files",` +
+			`route="/api/files/:module/:artifact/versions/latest",status="200"} 1`,
+		`go_index_skipped_versions_total{repository="files"} 2`,
+		`go_index_provider_call_duration_seconds_count{operation="ListObjectsV2",repository="files"} 2`,
+		`go_index_provider_call_errors_total{operation="ListObjectsV2",repository="files"} 1`,
+		`go_index_cache_requests_total{repository="files",result="hit"} 3`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Metrics output is missing %q:\n%s", line, body)
+		}
+	}
+
+	if strings.Contains(body, `go_index_provider_call_errors_total{operation="GetObject",repository="files"}`) {
+		t.Error("Missing files must not count as provider errors")
+	}
+}
+
+func TestProviderObserverSkipsMissingFilesAndCancellation(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	appMetrics := metrics.New()
+	observer := appMetrics.ProviderObserver("files")
+
+	provider := &providers.S3Provider{Client: mocks.NewMockS3Client(mockCtrl), Bucket: "test-bucket"}
+	provider.SetObserver(observer)
+
+	if _, err := provider.Open(context.Background(), "fe/app2/app2-1.0.0.txt"); !errors.Is(err, providers.ErrFileNotFound) {
+		t.Fatalf("Open returned %v; want %v", err, providers.ErrFileNotFound)
+	}
+
+	_, err := provider.Checksums(context.Background(), providers.Artifact{Path: "fe/app2/app2-1.0.0.txt"})
+	if !errors.Is(err, providers.ErrFileNotFound) {
+		t.Fatalf("Checksums returned %v; want %v", err, providers.ErrFileNotFound)
+	}
+
+	observer("ListObjectsV2", time.Millisecond, context.Canceled)
+	observer("ListObjectsV2", time.Millisecond, fmt.Errorf("operation error S3: %w", context.DeadlineExceeded))
+
+	router := gin.New()
+	router.GET("/metrics", appMetrics.Handler)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := recorder.Body.String()
+
+	if !strings.Contains(body, `go_index_provider_call_duration_seconds_count{operation="GetObject",repository="files"} 1`) {
+		t.Errorf("Metrics output is missing the GetObject call:\n%s", body)
+	}
+
+	if strings.Contains(body, "go_index_provider_call_errors_total{") {
+		t.Errorf("Missing objects and cancelled calls must not count as provider errors:\n%s", body)
+	}
+}
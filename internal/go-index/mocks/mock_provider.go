@@ -1,22 +1,76 @@
 package mocks
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
 	"github.com/golang/mock/gomock"
 	"github.com/mauhlik/go-index/internal/go-index/providers"
 )
 
 type MockProvider struct {
 	providers.Provider
-	mock *gomock.Controller
+	mock     *gomock.Controller
+	versions []string
 }
 
 func NewMockProvider(ctrl *gomock.Controller) *MockProvider {
+	return NewMockProviderWithVersions(ctrl, []string{"0.0.0", "0.0.1", "1.0.0", "2.0.0"})
+}
+
+func NewMockProviderWithVersions(ctrl *gomock.Controller, versions []string) *MockProvider {
 	return &MockProvider{
 		Provider: nil,
 		mock:     ctrl,
+		versions: versions,
+	}
+}
+
+func (m *MockProvider) GetVersions(_ context.Context, _, _ string) ([]string, error) {
+	return m.versions, nil
+}
+
+func (m *MockProvider) ListArtifacts(_ context.Context, moduleName, artifactName,
+	version string) ([]providers.Artifact, error) {
+	var artifacts []providers.Artifact
+
+	for _, candidate := range m.versions {
+		if version != "" && candidate != version {
+			continue
+		}
+
+		name := artifactName + "-" + candidate + ".txt"
+		artifacts = append(artifacts, providers.Artifact{
+			Path:         moduleName + "/" + artifactName + "/" + name,
+			Name:         name,
+			Version:      candidate,
+			Size:         int64(len(name)),
+			LastModified: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			ContentType:  "text/plain; charset=utf-8",
+			Checksums:    map[string]string{"sha256": "checksum-" + candidate},
+		})
 	}
+
+	return artifacts, nil
+}
+
+func (m *MockProvider) ListFiles(_ context.Context, _, artifactName string) ([]string, error) {
+	files := make([]string, 0, len(m.versions))
+
+	for _, version := range m.versions {
+		files = append(files, artifactName+"-"+version+".txt")
+	}
+
+	return files, nil
+}
+
+func (m *MockProvider) GetFile(_ context.Context, moduleName, artifactName, filename string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(fmt.Sprintf("%s/%s/%s", moduleName, artifactName, filename))), nil
 }
 
-func (m *MockProvider) GetVersions(_, _ string) ([]string, error) {
-	return []string{"0.0.0", "0.0.1", "1.0.0", "2.0.0"}, nil
+func (m *MockProvider) Open(_ context.Context, path string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(path)), nil
 }
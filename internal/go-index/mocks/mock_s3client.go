@@ -2,11 +2,19 @@ package mocks
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/middleware"
 	"github.com/golang/mock/gomock"
 )
@@ -17,20 +25,30 @@ const size = 123
 type MockS3Client struct {
 	s3.Client
 	mock *gomock.Controller
+
+	mutex     sync.Mutex
+	Uploads   map[string][]byte
+	checksums map[string]string
+	parts     map[string][][]byte
+	Deleted   []string
 }
 
 func NewMockS3Client(ctrl *gomock.Controller) *MockS3Client {
 	return &MockS3Client{
-		Client: s3.Client{},
-		mock:   ctrl,
+		Client:    s3.Client{},
+		mock:      ctrl,
+		mutex:     sync.Mutex{},
+		Uploads:   map[string][]byte{},
+		checksums: map[string]string{},
+		parts:     map[string][][]byte{},
+		Deleted:   nil,
 	}
 }
 
 func (m *MockS3Client) ListObjectsV2(_ context.Context, _ *s3.ListObjectsV2Input,
 	_ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
 	objects := []types.Object{}
-	keys := []string{"fe/app1/app1-0.0.0.txt", "fe/app1/app1-0.0.1.txt",
-		"fe/app1/app1-1.0.0.txt", "fe/app1/app1-2.0.0.txt"}
+	keys := listedKeys()
 	etags := []string{"etag-0.0.0", "etag-0.0.1", "etag-1.0.0", "etag-2.0.0"}
 
 	for i, key := range keys {
@@ -68,3 +86,168 @@ func (m *MockS3Client) ListObjectsV2(_ context.Context, _ *s3.ListObjectsV2Input
 
 	return output, nil
 }
+
+func (m *MockS3Client) GetObject(_ context.Context, input *s3.GetObjectInput,
+	_ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	key := aws.StringValue(input.Key)
+
+	if !strings.HasPrefix(key, "fe/app1/") {
+		return nil, &types.NoSuchKey{Message: aws.String("The specified key does not exist.")}
+	}
+
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(strings.NewReader(key)),
+		ContentLength: aws.Int64(int64(len(key))),
+		ETag:          aws.String("etag-" + key),
+		LastModified:  aws.Time(time.Now()),
+	}, nil
+}
+
+// exists reports keys returned by ListObjectsV2 or uploaded earlier, which conditional writes must not replace.
+func (m *MockS3Client) exists(key string) bool {
+	_, uploaded := m.Uploads[key]
+
+	return uploaded || slices.Contains(listedKeys(), key)
+}
+
+func listedKeys() []string {
+	return []string{"fe/app1/app1-0.0.0.txt", "fe/app1/app1-0.0.1.txt", "fe/app1/app1-1.0.0.txt", "fe/app1/app1-2.0.0.txt"}
+}
+
+func preconditionFailed() error {
+	return &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions failed",
+		Fault: smithy.FaultClient}
+}
+
+func (m *MockS3Client) PutObject(_ context.Context, input *s3.PutObjectInput,
+	_ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := aws.StringValue(input.Key)
+	if aws.StringValue(input.IfNoneMatch) == "*" && m.exists(key) {
+		return nil, preconditionFailed()
+	}
+
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Uploads[key] = data
+
+	if input.ChecksumSHA256 != nil {
+		sum := sha256.Sum256(data)
+		if checksum := base64.StdEncoding.EncodeToString(sum[:]); checksum != aws.StringValue(input.ChecksumSHA256) {
+			return nil, &smithy.GenericAPIError{Code: "BadDigest", Message: "checksum mismatch", Fault: smithy.FaultClient}
+		}
+
+		m.checksums[key] = aws.StringValue(input.ChecksumSHA256)
+	}
+
+	return &s3.PutObjectOutput{ETag: aws.String(`"etag-` + key + `"`)}, nil
+}
+
+func (m *MockS3Client) CreateMultipartUpload(_ context.Context, input *s3.CreateMultipartUploadInput,
+	_ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	uploadID := "upload-" + aws.StringValue(input.Key)
+	m.parts[uploadID] = nil
+
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String(uploadID)}, nil
+}
+
+func (m *MockS3Client) UploadPart(_ context.Context, input *s3.UploadPartInput,
+	_ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadID := aws.StringValue(input.UploadId)
+	m.parts[uploadID] = append(m.parts[uploadID], data)
+
+	return &s3.UploadPartOutput{ETag: aws.String(fmt.Sprintf(`"part-%d"`, aws.Int32Value(input.PartNumber)))}, nil
+}
+
+func (m *MockS3Client) CompleteMultipartUpload(_ context.Context, input *s3.CompleteMultipartUploadInput,
+	_ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := aws.StringValue(input.Key)
+	if aws.StringValue(input.IfNoneMatch) == "*" && m.exists(key) {
+		return nil, preconditionFailed()
+	}
+
+	var data []byte
+	for _, part := range m.parts[aws.StringValue(input.UploadId)] {
+		data = append(data, part...)
+	}
+
+	m.Uploads[key] = data
+
+	return &s3.CompleteMultipartUploadOutput{ETag: aws.String(`"etag-` + key + `"`)}, nil
+}
+
+func (m *MockS3Client) AbortMultipartUpload(_ context.Context, input *s3.AbortMultipartUploadInput,
+	_ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.parts, aws.StringValue(input.UploadId))
+
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func (m *MockS3Client) HeadBucket(_ context.Context, input *s3.HeadBucketInput,
+	_ ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	if aws.StringValue(input.Bucket) != "test-bucket" {
+		return nil, &types.NotFound{Message: aws.String("Not Found")}
+	}
+
+	return &s3.HeadBucketOutput{}, nil
+}
+
+// HeadObject returns the SHA-256 of objects uploaded with one, and a composite checksum for multipart uploads.
+func (m *MockS3Client) HeadObject(_ context.Context, input *s3.HeadObjectInput,
+	_ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := aws.StringValue(input.Key)
+	if !m.exists(key) {
+		return nil, &types.NotFound{Message: aws.String("Not Found")}
+	}
+
+	output := &s3.HeadObjectOutput{ETag: aws.String(`"etag-` + key + `"`)}
+
+	if checksum, ok := m.checksums[key]; ok && input.ChecksumMode == types.ChecksumModeEnabled {
+		output.ChecksumSHA256 = aws.String(checksum)
+		output.ChecksumType = types.ChecksumTypeFullObject
+	} else if _, ok := m.Uploads[key]; ok && input.ChecksumMode == types.ChecksumModeEnabled {
+		output.ChecksumSHA256 = aws.String("composite-1")
+		output.ChecksumType = types.ChecksumTypeComposite
+	}
+
+	return output, nil
+}
+
+// DeleteObject removes uploaded objects and records every deleted key, since listed keys cannot be removed.
+func (m *MockS3Client) DeleteObject(_ context.Context, input *s3.DeleteObjectInput,
+	_ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := aws.StringValue(input.Key)
+	delete(m.Uploads, key)
+	delete(m.checksums, key)
+	m.Deleted = append(m.Deleted, key)
+
+	return &s3.DeleteObjectOutput{}, nil
+}
@@ -0,0 +1,446 @@
+package providers
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cacheOpVersions  = "versions"
+	cacheOpArtifacts = "artifacts"
+	cacheOpFiles     = "files"
+
+	DefaultCacheMaxEntries = 10000
+	// cacheMinSweepInterval keeps very short TTLs from turning the sweep into a busy loop.
+	cacheMinSweepInterval = time.Second
+)
+
+type cacheKey struct {
+	op       string
+	module   string
+	artifact string
+	version  string
+}
+
+type cacheEntry struct {
+	key       cacheKey
+	value     interface{}
+	fetchedAt time.Time
+}
+
+type cacheCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+type CacheStats struct {
+	Hits      uint64
+	StaleHits uint64
+	Misses    uint64
+	Coalesced uint64
+}
+
+// CachingProvider caches listings of another provider. Entries younger than ttl are served directly,
+// entries within the following staleTTL are served while a background refresh runs, and concurrent
+// loads of the same key share a single call to the wrapped provider. Errors and empty listings are never
+// cached, the least recently used entry is evicted beyond maxEntries, and expired entries are swept
+// periodically so listings nobody asks for again do not stay in memory.
+type CachingProvider struct {
+	Provider
+
+	name       string
+	ttl        time.Duration
+	staleTTL   time.Duration
+	maxEntries int
+	logger     *logrus.Logger
+
+	mutex   sync.Mutex
+	entries map[cacheKey]*list.Element
+	// recent orders the entries from most to least recently used.
+	recent     *list.List
+	calls      map[cacheKey]*cacheCall
+	generation uint64
+
+	closing context.Context
+	close   context.CancelFunc
+	loads   sync.WaitGroup
+	swept   chan struct{}
+
+	hits      atomic.Uint64
+	staleHits atomic.Uint64
+	misses    atomic.Uint64
+	coalesced atomic.Uint64
+}
+
+// NewCachingProvider keeps at most maxEntries listings, DefaultCacheMaxEntries when it is not positive.
+// Close stops the sweep.
+func NewCachingProvider(provider Provider, name string, ttl, staleTTL time.Duration, maxEntries int,
+	logger *logrus.Logger) *CachingProvider {
+	closing, closeFunc := context.WithCancel(context.Background())
+
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+
+	cache := &CachingProvider{
+		Provider:   provider,
+		name:       name,
+		ttl:        ttl,
+		staleTTL:   staleTTL,
+		maxEntries: maxEntries,
+		logger:     logger,
+		mutex:      sync.Mutex{},
+		entries:    map[cacheKey]*list.Element{},
+		recent:     list.New(),
+		calls:      map[cacheKey]*cacheCall{},
+		generation: 0,
+		closing:    closing,
+		close:      closeFunc,
+		loads:      sync.WaitGroup{},
+		swept:      make(chan struct{}),
+		hits:       atomic.Uint64{},
+		staleHits:  atomic.Uint64{},
+		misses:     atomic.Uint64{},
+		coalesced:  atomic.Uint64{},
+	}
+
+	go cache.sweep(max(ttl+staleTTL, cacheMinSweepInterval))
+
+	return cache
+}
+
+func (p *CachingProvider) GetVersions(ctx context.Context, moduleName, artifactName string) ([]string, error) {
+	key := cacheKey{op: cacheOpVersions, module: moduleName, artifact: artifactName, version: ""}
+
+	value, err := p.get(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return p.Provider.GetVersions(ctx, moduleName, artifactName)
+	})
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	versions, _ := value.([]string)
+
+	return slices.Clone(versions), nil
+}
+
+func (p *CachingProvider) ListArtifacts(ctx context.Context, moduleName, artifactName,
+	version string) ([]Artifact, error) {
+	key := cacheKey{op: cacheOpArtifacts, module: moduleName, artifact: artifactName, version: version}
+
+	value, err := p.get(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return p.Provider.ListArtifacts(ctx, moduleName, artifactName, version)
+	})
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	artifacts, _ := value.([]Artifact)
+
+	return slices.Clone(artifacts), nil
+}
+
+func (p *CachingProvider) ListFiles(ctx context.Context, moduleName, artifactName string) ([]string, error) {
+	key := cacheKey{op: cacheOpFiles, module: moduleName, artifact: artifactName, version: ""}
+
+	value, err := p.get(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return p.Provider.ListFiles(ctx, moduleName, artifactName)
+	})
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	files, _ := value.([]string)
+
+	return slices.Clone(files), nil
+}
+
+func (p *CachingProvider) PresignGet(ctx context.Context, path string, expires time.Duration) (string, error) {
+	presigner, ok := p.Provider.(Presigner)
+	if !ok {
+		return "", ErrPresignUnsupported
+	}
+
+	return presigner.PresignGet(ctx, path, expires) //nolint:wrapcheck
+}
+
+// Checksums forwards to the inner provider, whose own cache knows when files change; listings of providers
+// that are not Checksummers already hold their checksums.
+func (p *CachingProvider) Checksums(ctx context.Context, artifact Artifact) (map[string]string, error) {
+	checksummer, ok := p.Provider.(Checksummer)
+	if !ok {
+		return artifact.Checksums, nil
+	}
+
+	return checksummer.Checksums(ctx, artifact) //nolint:wrapcheck
+}
+
+// Publish drops every cached listing of the artifact so the new file is visible immediately.
+func (p *CachingProvider) Publish(ctx context.Context, moduleName, artifactName, version, filename string,
+	body io.Reader) (Artifact, error) {
+	publisher, ok := p.Provider.(Publisher)
+	if !ok {
+		return Artifact{}, ErrPublishUnsupported
+	}
+
+	artifact, err := publisher.Publish(ctx, moduleName, artifactName, version, filename, body)
+
+	p.Invalidate(moduleName, artifactName)
+
+	return artifact, err //nolint:wrapcheck
+}
+
+// Delete drops every cached listing of the artifact, like Publish.
+func (p *CachingProvider) Delete(ctx context.Context, moduleName, artifactName, path string) error {
+	deleter, ok := p.Provider.(Deleter)
+	if !ok {
+		return ErrDeleteUnsupported
+	}
+
+	err := deleter.Delete(ctx, moduleName, artifactName, path)
+
+	p.Invalidate(moduleName, artifactName)
+
+	return err //nolint:wrapcheck
+}
+
+// ListModules is not cached; it pages through the whole repository and a publish can add to any page.
+func (p *CachingProvider) ListModules(ctx context.Context, page Page) (Listing, error) {
+	lister, ok := p.Provider.(Lister)
+	if !ok {
+		return Listing{}, ErrListingUnsupported
+	}
+
+	return lister.ListModules(ctx, page) //nolint:wrapcheck
+}
+
+func (p *CachingProvider) ListArtifactNames(ctx context.Context, moduleName string, page Page) (Listing, error) {
+	lister, ok := p.Provider.(Lister)
+	if !ok {
+		return Listing{}, ErrListingUnsupported
+	}
+
+	return lister.ListArtifactNames(ctx, moduleName, page) //nolint:wrapcheck
+}
+
+// Check always asks the wrapped provider; readiness must not be answered from the cache.
+func (p *CachingProvider) Check(ctx context.Context) error {
+	checker, ok := p.Provider.(Checker)
+	if !ok {
+		return nil
+	}
+
+	return checker.Check(ctx) //nolint:wrapcheck
+}
+
+func (p *CachingProvider) Invalidate(moduleName, artifactName string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.generation++
+
+	for key, element := range p.entries {
+		if key.module == moduleName && key.artifact == artifactName {
+			p.remove(element)
+		}
+	}
+}
+
+func (p *CachingProvider) Close() error {
+	p.close()
+	p.loads.Wait()
+	<-p.swept
+
+	return nil
+}
+
+func (p *CachingProvider) Stats() CacheStats {
+	return CacheStats{
+		Hits:      p.hits.Load(),
+		StaleHits: p.staleHits.Load(),
+		Misses:    p.misses.Load(),
+		Coalesced: p.coalesced.Load(),
+	}
+}
+
+// get stops waiting when ctx is done, but the shared load keeps running for the other callers and the
+// cache; it is only bounded by the deadline of the request that started it and by Close.
+func (p *CachingProvider) get(ctx context.Context, key cacheKey,
+	load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	p.mutex.Lock()
+
+	if element, ok := p.entries[key]; ok {
+		entry, _ := element.Value.(*cacheEntry)
+		age := time.Since(entry.fetchedAt)
+
+		switch {
+		case age < p.ttl:
+			p.recent.MoveToFront(element)
+			p.mutex.Unlock()
+			p.hits.Add(1)
+			p.logger.Debugf("Cache hit for %s %s/%s in repository %s", key.op, key.module, key.artifact, p.name)
+
+			return entry.value, nil
+		case age < p.ttl+p.staleTTL:
+			p.recent.MoveToFront(element)
+			p.start(ctx, key, load)
+			p.mutex.Unlock()
+			p.staleHits.Add(1)
+			p.logger.Debugf("Serving stale %s %s/%s in repository %s while refreshing", key.op, key.module,
+				key.artifact, p.name)
+
+			return entry.value, nil
+		}
+
+		p.remove(element)
+	}
+
+	call := p.start(ctx, key, load)
+	p.mutex.Unlock()
+	p.misses.Add(1)
+	p.logger.Debugf("Cache miss for %s %s/%s in repository %s", key.op, key.module, key.artifact, p.name)
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to load %s %s/%s: %w", key.op, key.module, key.artifact, ctx.Err())
+	}
+}
+
+// start returns the in-flight load of key or begins one; the caller must hold the mutex.
+func (p *CachingProvider) start(ctx context.Context, key cacheKey,
+	load func(ctx context.Context) (interface{}, error)) *cacheCall {
+	if call, ok := p.calls[key]; ok {
+		p.coalesced.Add(1)
+
+		return call
+	}
+
+	call := &cacheCall{done: make(chan struct{}), value: nil, err: nil}
+	p.calls[key] = call
+	generation := p.generation
+
+	loadCtx, cancel := p.loadContext(ctx)
+
+	p.loads.Add(1)
+
+	go func() {
+		defer p.loads.Done()
+		defer cancel()
+
+		value, err := load(loadCtx)
+		if err != nil {
+			p.logger.WithError(err).Warnf("Failed to refresh %s %s/%s in repository %s", key.op, key.module,
+				key.artifact, p.name)
+		}
+
+		p.mutex.Lock()
+		delete(p.calls, key)
+
+		// A publish during the load may have made the result outdated already. An empty listing is not
+		// cached: it is cheap to ask for again, and caching it would let requests for made-up names fill
+		// the cache.
+		if err == nil && generation == p.generation && !isEmptyListing(value) {
+			p.store(key, value)
+		}
+
+		p.mutex.Unlock()
+
+		call.value, call.err = value, err
+		close(call.done)
+	}()
+
+	return call
+}
+
+// store adds or replaces the entry of key and evicts the least recently used entries beyond maxEntries;
+// the caller must hold the mutex.
+func (p *CachingProvider) store(key cacheKey, value interface{}) {
+	if element, ok := p.entries[key]; ok {
+		p.remove(element)
+	}
+
+	p.entries[key] = p.recent.PushFront(&cacheEntry{key: key, value: value, fetchedAt: time.Now()})
+
+	for p.recent.Len() > p.maxEntries {
+		p.remove(p.recent.Back())
+	}
+}
+
+// remove drops an entry; the caller must hold the mutex.
+func (p *CachingProvider) remove(element *list.Element) {
+	entry, _ := p.recent.Remove(element).(*cacheEntry)
+	delete(p.entries, entry.key)
+}
+
+// sweep drops entries past their stale TTL every interval until the cache is closed.
+func (p *CachingProvider) sweep(interval time.Duration) {
+	defer close(p.swept)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.closing.Done():
+			return
+		case <-ticker.C:
+			p.removeExpired()
+		}
+	}
+}
+
+func (p *CachingProvider) removeExpired() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Entries are not ordered by age, since hits move them to the front, so every entry is checked.
+	for _, element := range p.entries {
+		entry, _ := element.Value.(*cacheEntry)
+		if time.Since(entry.fetchedAt) >= p.ttl+p.staleTTL {
+			p.remove(element)
+		}
+	}
+}
+
+func isEmptyListing(value interface{}) bool {
+	switch listing := value.(type) {
+	case []string:
+		return len(listing) == 0
+	case []Artifact:
+		return len(listing) == 0
+	default:
+		return false
+	}
+}
+
+func (p *CachingProvider) loadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	loadCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(p.closing, cancel)
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return loadCtx, func() {
+			stop()
+			cancel()
+		}
+	}
+
+	loadCtx, cancelDeadline := context.WithDeadline(loadCtx, deadline)
+
+	return loadCtx, func() {
+		cancelDeadline()
+		stop()
+		cancel()
+	}
+}
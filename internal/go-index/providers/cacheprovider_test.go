@@ -0,0 +1,260 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/sirupsen/logrus"
+)
+
+var errBackend = errors.New("backend unavailable")
+
+type countingProvider struct {
+	providers.Provider
+	calls    atomic.Int32
+	release  chan struct{}
+	versions atomic.Value
+	fail     atomic.Bool
+}
+
+func newCountingProvider(versions ...string) *countingProvider {
+	provider := &countingProvider{release: nil}
+	provider.versions.Store(versions)
+
+	return provider
+}
+
+func (p *countingProvider) GetVersions(ctx context.Context, _, _ string) ([]string, error) {
+	p.calls.Add(1)
+
+	if p.release != nil {
+		select {
+		case <-p.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if p.fail.Load() {
+		return nil, errBackend
+	}
+
+	versions, _ := p.versions.Load().([]string)
+
+	return versions, nil
+}
+
+func (p *countingProvider) Publish(_ context.Context, _, _, version, filename string, _ io.Reader) (providers.Artifact, error) {
+	versions, _ := p.versions.Load().([]string)
+	p.versions.Store(append(append([]string{}, versions...), version))
+
+	return providers.Artifact{Path: filename, Name: filename, Version: version}, nil
+}
+
+func TestCachingProviderHitAndCoalescing(t *testing.T) {
+	t.Parallel()
+
+	backend := newCountingProvider("1.0.0")
+	backend.release = make(chan struct{})
+	cache := providers.NewCachingProvider(backend, "test", time.Minute, 0, 0, logrus.New())
+
+	var waitGroup sync.WaitGroup
+
+	for range 10 {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			if versions, err := cache.GetVersions(context.Background(), "fe", "app1"); err != nil || len(versions) != 1 {
+				t.Errorf("GetVersions returned %v, %v; want [1.0.0]", versions, err)
+			}
+		}()
+	}
+
+	for cache.Stats().Misses+cache.Stats().Coalesced < 10 {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(backend.release)
+	waitGroup.Wait()
+
+	if _, err := cache.GetVersions(context.Background(), "fe", "app1"); err != nil {
+		t.Fatalf("GetVersions returned an error: %v", err)
+	}
+
+	if calls := backend.calls.Load(); calls != 1 {
+		t.Errorf("Backend was called %d times; want 1", calls)
+	}
+
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 10 || stats.Coalesced != 9 {
+		t.Errorf("Stats are %+v; want 1 hit, 10 misses and 9 coalesced", stats)
+	}
+}
+
+func TestCachingProviderStaleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+
+	backend := newCountingProvider("1.0.0")
+	cache := providers.NewCachingProvider(backend, "test", time.Nanosecond, time.Hour, 0, logrus.New())
+
+	if _, err := cache.GetVersions(context.Background(), "fe", "app1"); err != nil {
+		t.Fatalf("GetVersions returned an error: %v", err)
+	}
+
+	backend.versions.Store([]string{"1.0.0", "2.0.0"})
+
+	versions, err := cache.GetVersions(context.Background(), "fe", "app1")
+	if err != nil || len(versions) != 1 {
+		t.Errorf("GetVersions returned %v, %v; want the stale [1.0.0]", versions, err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for backend.calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	for time.Now().Before(deadline) {
+		if versions, _ := cache.GetVersions(context.Background(), "fe", "app1"); len(versions) == 2 {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Error("GetVersions never returned the refreshed versions")
+}
+
+func TestCachingProviderErrorsAndInvalidation(t *testing.T) {
+	t.Parallel()
+
+	backend := newCountingProvider("1.0.0")
+	backend.fail.Store(true)
+	cache := providers.NewCachingProvider(backend, "test", time.Hour, 0, 0, logrus.New())
+
+	if _, err := cache.GetVersions(context.Background(), "fe", "app1"); !errors.Is(err, errBackend) {
+		t.Fatalf("GetVersions returned %v; want %v", err, errBackend)
+	}
+
+	backend.fail.Store(false)
+
+	if versions, err := cache.GetVersions(context.Background(), "fe", "app1"); err != nil || len(versions) != 1 {
+		t.Fatalf("GetVersions returned %v, %v; want [1.0.0] after the error", versions, err)
+	}
+
+	if _, err := cache.Publish(context.Background(), "fe", "app1", "2.0.0", "app1-2.0.0.txt", nil); err != nil {
+		t.Fatalf("Publish returned an error: %v", err)
+	}
+
+	if versions, err := cache.GetVersions(context.Background(), "fe", "app1"); err != nil || len(versions) != 2 {
+		t.Errorf("GetVersions returned %v, %v; want the published version", versions, err)
+	}
+
+	if err := cache.Delete(context.Background(), "fe", "app1", "fe/app1/app1-1.0.0.txt"); !errors.Is(err,
+		providers.ErrDeleteUnsupported) {
+		t.Errorf("Delete returned %v; want %v", err, providers.ErrDeleteUnsupported)
+	}
+
+	if _, err := cache.PresignGet(context.Background(), "fe/app1/app1-1.0.0.txt", time.Minute); !errors.Is(err, providers.ErrPresignUnsupported) {
+		t.Errorf("PresignGet returned %v; want %v", err, providers.ErrPresignUnsupported)
+	}
+}
+
+func TestCachingProviderCallerCancellation(t *testing.T) {
+	t.Parallel()
+
+	backend := newCountingProvider("1.0.0")
+	backend.release = make(chan struct{})
+	cache := providers.NewCachingProvider(backend, "test", time.Hour, 0, 0, logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	if _, err := cache.GetVersions(ctx, "fe", "app1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetVersions returned %v; want %v", err, context.Canceled)
+	}
+
+	close(backend.release)
+
+	deadline := time.Now().Add(time.Second)
+	for cache.Stats().Hits == 0 && time.Now().Before(deadline) {
+		if _, err := cache.GetVersions(context.Background(), "fe", "app1"); err != nil {
+			t.Fatalf("GetVersions returned an error: %v", err)
+		}
+	}
+
+	if calls := backend.calls.Load(); calls != 1 {
+		t.Errorf("Backend was called %d times; want the abandoned load to fill the cache", calls)
+	}
+}
+
+func TestCachingProviderClose(t *testing.T) {
+	t.Parallel()
+
+	backend := newCountingProvider("1.0.0")
+	backend.release = make(chan struct{})
+	cache := providers.NewCachingProvider(backend, "test", time.Hour, 0, 0, logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := cache.GetVersions(ctx, "fe", "app1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetVersions returned %v; want %v", err, context.Canceled)
+	}
+
+	closed := make(chan struct{})
+
+	go func() {
+		cache.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not stop the running load")
+	}
+}
+
+func TestCachingProviderEviction(t *testing.T) {
+	t.Parallel()
+
+	backend := newCountingProvider("1.0.0")
+	cache := providers.NewCachingProvider(backend, "test", time.Hour, 0, 2, logrus.New())
+	defer cache.Close()
+
+	// app1 is used again before app3 is loaded, so app2 is the least recently used entry and is evicted.
+	for _, artifact := range []string{"app1", "app2", "app1", "app3", "app1", "app2"} {
+		if _, err := cache.GetVersions(context.Background(), "fe", artifact); err != nil {
+			t.Fatalf("GetVersions returned an error: %v", err)
+		}
+	}
+
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 4 {
+		t.Errorf("Stats are %+v; want 2 hits and 4 misses", stats)
+	}
+}
+
+func TestCachingProviderSkipsEmptyListings(t *testing.T) {
+	t.Parallel()
+
+	backend := newCountingProvider()
+	cache := providers.NewCachingProvider(backend, "test", time.Hour, 0, 0, logrus.New())
+	defer cache.Close()
+
+	for range 2 {
+		if _, err := cache.GetVersions(context.Background(), "fe", "missing"); err != nil {
+			t.Fatalf("GetVersions returned an error: %v", err)
+		}
+	}
+
+	if calls := backend.calls.Load(); calls != 2 {
+		t.Errorf("Backend was called %d times; want every lookup of an empty listing to reach it", calls)
+	}
+}
@@ -0,0 +1,477 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+)
+
+const (
+	placeholderModule   = "module"
+	placeholderArtifact = "artifact"
+	placeholderVersion  = "version"
+)
+
+var (
+	ErrInvalidLayout  = errors.New("invalid layout")
+	ErrLayoutMismatch = errors.New("file does not match the repository layout")
+
+	placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// Layout maps storage paths (slash separated, relative to the provider root) to artifact versions.
+type Layout interface {
+	// Prefix is the directory every file of the artifact lives under; providers only list below it.
+	Prefix(moduleName, artifactName string) string
+	Match(moduleName, artifactName, path string) (string, bool)
+	// Path is where a published file is stored; it must still be accepted by Match.
+	Path(moduleName, artifactName, version, filename string) string
+	Recursive() bool
+}
+
+// DirectoryLayout is implemented by layouts that can keep modules and artifacts in directories of their
+// own, so they can be discovered by listing directories. The prefixes end with a slash unless empty.
+type DirectoryLayout interface {
+	ModulesPrefix() (string, bool)
+	ArtifactsPrefix(moduleName string) (string, bool)
+}
+
+// DefaultLayout is <module>/<artifact>/<artifact>-<version>.<ext>.
+type DefaultLayout struct{}
+
+func (DefaultLayout) Prefix(moduleName, artifactName string) string {
+	return moduleName + "/" + artifactName + "/"
+}
+
+func (l DefaultLayout) Match(moduleName, artifactName, path string) (string, bool) {
+	filename, ok := strings.CutPrefix(path, l.Prefix(moduleName, artifactName))
+	if !ok || strings.Contains(filename, "/") {
+		return "", false
+	}
+
+	version := ExtractVersionFromFilename(filename, artifactName)
+
+	return version, version != ""
+}
+
+func (l DefaultLayout) Path(moduleName, artifactName, _, filename string) string {
+	return l.Prefix(moduleName, artifactName) + filename
+}
+
+func (DefaultLayout) Recursive() bool {
+	return false
+}
+
+func (DefaultLayout) ModulesPrefix() (string, bool) {
+	return "", true
+}
+
+func (DefaultLayout) ArtifactsPrefix(moduleName string) (string, bool) {
+	return moduleName + "/", true
+}
+
+// TemplateLayout expands placeholders such as releases/{module}/{version}/{artifact}_{version}_{os}.tar.gz.
+// {module} and {artifact} must equal the requested names, {version} is captured and any other placeholder
+// matches a single path segment. A trailing slash means the version is a directory and any file below it
+// belongs to it.
+//
+// The template is parsed once and matched by backtracking rather than compiled to a regular expression per
+// module and artifact, which would cache one per name ever requested. A single regular expression with the
+// names as capture groups is not enough either: RE2 picks one parse, so for {artifact}-{version}.zip it
+// splits my-app-1.0.zip as "my" and "app-1.0" and the artifact my-app would never match.
+type TemplateLayout struct {
+	template  string
+	tokens    []templateToken
+	directory bool
+}
+
+// templateToken is literal text, or a placeholder when name is set.
+type templateToken struct {
+	literal string
+	name    string
+}
+
+func NewTemplateLayout(template string) (*TemplateLayout, error) {
+	if strings.HasPrefix(template, "/") {
+		return nil, fmt.Errorf("%w: template must be relative: %s", ErrInvalidLayout, template)
+	}
+
+	var tokens []templateToken
+
+	hasVersion := false
+	last := 0
+
+	for _, location := range placeholderPattern.FindAllStringSubmatchIndex(template, -1) {
+		if location[0] > last {
+			tokens = append(tokens, templateToken{literal: template[last:location[0]], name: ""})
+		}
+
+		name := template[location[2]:location[3]]
+		hasVersion = hasVersion || name == placeholderVersion
+		tokens = append(tokens, templateToken{literal: "", name: name})
+		last = location[1]
+	}
+
+	if !hasVersion {
+		return nil, fmt.Errorf("%w: template has no {version} placeholder: %s", ErrInvalidLayout, template)
+	}
+
+	if last < len(template) {
+		tokens = append(tokens, templateToken{literal: template[last:], name: ""})
+	}
+
+	return &TemplateLayout{template: template, tokens: tokens, directory: strings.HasSuffix(template, "/")}, nil
+}
+
+func (l *TemplateLayout) Prefix(moduleName, artifactName string) string {
+	expanded := l.substitute(moduleName, artifactName)
+
+	if index := strings.Index(expanded, "{"); index >= 0 {
+		expanded = expanded[:index]
+	}
+
+	return expanded[:strings.LastIndex(expanded, "/")+1]
+}
+
+func (l *TemplateLayout) Match(moduleName, artifactName, path string) (string, bool) {
+	version, ok := l.match(l.tokens, path, map[string]string{
+		placeholderModule:   moduleName,
+		placeholderArtifact: artifactName,
+	})
+
+	return version, ok && version != ""
+}
+
+// match reports whether path matches tokens given the placeholder values bound so far, and returns the
+// version. Unbound placeholders take the shortest text that lets the rest match, so the first version
+// found is the one a non-greedy regular expression would capture.
+func (l *TemplateLayout) match(tokens []templateToken, path string, values map[string]string) (string, bool) {
+	if len(tokens) == 0 {
+		return values[placeholderVersion], path == "" || l.directory
+	}
+
+	token := tokens[0]
+
+	if token.name == "" {
+		rest, ok := strings.CutPrefix(path, token.literal)
+		if !ok {
+			return "", false
+		}
+
+		return l.match(tokens[1:], rest, values)
+	}
+
+	if value, bound := values[token.name]; bound {
+		rest, ok := strings.CutPrefix(path, value)
+		if !ok || value == "" {
+			return "", false
+		}
+
+		return l.match(tokens[1:], rest, values)
+	}
+
+	segmentEnd := strings.Index(path, "/")
+	if segmentEnd < 0 {
+		segmentEnd = len(path)
+	}
+
+	for end := 1; end <= segmentEnd; end++ {
+		// Only {version} is compared when repeated; other placeholders match any segment text each time.
+		if token.name == placeholderVersion {
+			values[token.name] = path[:end]
+		}
+
+		if version, ok := l.match(tokens[1:], path[end:], values); ok {
+			return version, true
+		}
+	}
+
+	if token.name == placeholderVersion {
+		delete(values, placeholderVersion)
+	}
+
+	return "", false
+}
+
+// Path keeps the directory part of the expanded template and uses filename as the last segment.
+// Directories built from placeholders other than {module}, {artifact} and {version} cannot be derived.
+func (l *TemplateLayout) Path(moduleName, artifactName, version, filename string) string {
+	expanded := strings.ReplaceAll(l.substitute(moduleName, artifactName), "{"+placeholderVersion+"}", version)
+	directory := expanded[:strings.LastIndex(expanded, "/")+1]
+
+	if strings.Contains(directory, "{") {
+		return ""
+	}
+
+	return directory + filename
+}
+
+func (*TemplateLayout) Recursive() bool {
+	return true
+}
+
+// ModulesPrefix requires {module} to be a whole directory name below literal directories.
+func (l *TemplateLayout) ModulesPrefix() (string, bool) {
+	return l.directoryOf(placeholderModule, "")
+}
+
+// ArtifactsPrefix requires {artifact} to be a whole directory name below literal directories or {module}.
+func (l *TemplateLayout) ArtifactsPrefix(moduleName string) (string, bool) {
+	return l.directoryOf(placeholderArtifact, moduleName)
+}
+
+// directoryOf returns the directory holding one directory per value of placeholder.
+func (l *TemplateLayout) directoryOf(placeholder, moduleName string) (string, bool) {
+	segments := strings.Split(l.template, "/")
+
+	for index, segment := range segments[:len(segments)-1] {
+		switch {
+		case segment == "{"+placeholder+"}":
+			var prefix strings.Builder
+
+			for _, parent := range segments[:index] {
+				prefix.WriteString(strings.ReplaceAll(parent, "{"+placeholderModule+"}", moduleName) + "/")
+			}
+
+			return prefix.String(), true
+		case segment == "{"+placeholderModule+"}" && placeholder == placeholderArtifact:
+			// The module is known when listing its artifacts.
+		case strings.Contains(segment, "{"):
+			return "", false
+		}
+	}
+
+	return "", false
+}
+
+func (l *TemplateLayout) substitute(moduleName, artifactName string) string {
+	return strings.NewReplacer("{module}", moduleName, "{artifact}", artifactName).Replace(l.template)
+}
+
+// RegexLayout matches whole paths against a regular expression with a named "version" group and optional
+// "module" and "artifact" groups that must equal the requested module and artifact.
+type RegexLayout struct {
+	pattern *regexp.Regexp
+	parsed  *syntax.Regexp
+}
+
+func NewRegexLayout(expression string) (*RegexLayout, error) {
+	anchored := "^(?:" + strings.TrimSuffix(strings.TrimPrefix(expression, "^"), "$") + ")$"
+
+	pattern, err := regexp.Compile(anchored)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLayout, err)
+	}
+
+	if pattern.SubexpIndex(placeholderVersion) < 0 {
+		return nil, fmt.Errorf("%w: regex has no named version group: %s", ErrInvalidLayout, expression)
+	}
+
+	parsed, err := syntax.Parse(anchored, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLayout, err)
+	}
+
+	return &RegexLayout{pattern: pattern, parsed: parsed}, nil
+}
+
+// Prefix is the directory part of the literal text every match starts with. The "module" and "artifact"
+// groups count as literal text, since a path only matches when they equal the requested names.
+func (l *RegexLayout) Prefix(moduleName, artifactName string) string {
+	var prefix strings.Builder
+
+	collectLiteralPrefix(l.parsed, map[string]string{
+		placeholderModule:   moduleName,
+		placeholderArtifact: artifactName,
+	}, &prefix)
+
+	return prefix.String()[:strings.LastIndex(prefix.String(), "/")+1]
+}
+
+func (l *RegexLayout) Match(moduleName, artifactName, path string) (string, bool) {
+	match := l.pattern.FindStringSubmatch(path)
+	if match == nil {
+		return "", false
+	}
+
+	if index := l.pattern.SubexpIndex(placeholderModule); index >= 0 && match[index] != moduleName {
+		return "", false
+	}
+
+	if index := l.pattern.SubexpIndex(placeholderArtifact); index >= 0 && match[index] != artifactName {
+		return "", false
+	}
+
+	version := match[l.pattern.SubexpIndex(placeholderVersion)]
+
+	return version, version != ""
+}
+
+func (l *RegexLayout) Path(moduleName, artifactName, _, filename string) string {
+	return l.Prefix(moduleName, artifactName) + filename
+}
+
+func (*RegexLayout) Recursive() bool {
+	return true
+}
+
+// collectLiteralPrefix writes the literal text every match starts with, writing groups named in values
+// as their value, and reports whether the whole expression was literal, so the caller knows whether to
+// keep descending.
+func collectLiteralPrefix(expression *syntax.Regexp, values map[string]string, prefix *strings.Builder) bool {
+	switch expression.Op { //nolint:exhaustive
+	case syntax.OpLiteral:
+		if expression.Flags&syntax.FoldCase != 0 {
+			return false
+		}
+
+		prefix.WriteString(string(expression.Rune))
+
+		return true
+	case syntax.OpBeginText, syntax.OpBeginLine, syntax.OpEmptyMatch:
+		return true
+	case syntax.OpCapture:
+		if value, ok := values[expression.Name]; ok {
+			prefix.WriteString(value)
+
+			return true
+		}
+
+		return collectLiteralPrefix(expression.Sub[0], values, prefix)
+	case syntax.OpConcat:
+		for _, sub := range expression.Sub {
+			if !collectLiteralPrefix(sub, values, prefix) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
+
+//nolint:ireturn
+func NewLayout(template, expression string) (Layout, error) {
+	switch {
+	case template != "" && expression != "":
+		return nil, fmt.Errorf("%w: layout and layoutRegex are mutually exclusive", ErrInvalidLayout)
+	case template != "":
+		return NewTemplateLayout(template)
+	case expression != "":
+		return NewRegexLayout(expression)
+	default:
+		return DefaultLayout{}, nil
+	}
+}
+
+//nolint:ireturn
+func layoutOrDefault(layout Layout) Layout {
+	if layout == nil {
+		return DefaultLayout{}
+	}
+
+	return layout
+}
+
+// directoryPrefix returns where the modules, or the artifacts of moduleName when it is not empty, are listed.
+func directoryPrefix(layout Layout, moduleName string) (string, error) {
+	directories, ok := layoutOrDefault(layout).(DirectoryLayout)
+	if !ok {
+		return "", ErrListingUnsupported
+	}
+
+	prefix, ok := directories.ModulesPrefix()
+
+	if moduleName != "" {
+		if err := ValidatePath(moduleName); err != nil {
+			return "", err
+		}
+
+		if strings.Contains(moduleName, "/") {
+			return "", fmt.Errorf("%w: %s", ErrInvalidPath, moduleName)
+		}
+
+		prefix, ok = directories.ArtifactsPrefix(moduleName)
+	}
+
+	if !ok {
+		return "", ErrListingUnsupported
+	}
+
+	return prefix, nil
+}
+
+// publishPath resolves where filename is stored for version and checks the layout maps it back to that version.
+func publishPath(layout Layout, moduleName, artifactName, version, filename string) (string, error) {
+	for _, elem := range []string{moduleName, artifactName, version, filename} {
+		if err := ValidatePath(elem); err != nil {
+			return "", err
+		}
+	}
+
+	if strings.Contains(filename, "/") || strings.Contains(version, "/") {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPath, filename)
+	}
+
+	path := layout.Path(moduleName, artifactName, version, filename)
+	if path == "" {
+		return "", fmt.Errorf("%w: cannot derive a path for %s", ErrLayoutMismatch, filename)
+	}
+
+	if matched, ok := layout.Match(moduleName, artifactName, path); !ok || matched != version {
+		return "", fmt.Errorf("%w: %s is not a file of version %s", ErrLayoutMismatch, path, version)
+	}
+
+	return path, nil
+}
+
+func matchVersions(layout Layout, moduleName, artifactName string, paths []string) []string {
+	var versions []string
+
+	seen := make(map[string]bool)
+
+	for _, path := range paths {
+		version, ok := layout.Match(moduleName, artifactName, path)
+		if !ok || seen[version] {
+			continue
+		}
+
+		seen[version] = true
+		versions = append(versions, version)
+	}
+
+	return versions
+}
+
+func matchArtifacts(layout Layout, moduleName, artifactName, version string, paths []string) []Artifact {
+	var artifacts []Artifact
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "/") {
+			continue
+		}
+
+		pathVersion, ok := layout.Match(moduleName, artifactName, path)
+		if !ok || (version != "" && pathVersion != version) {
+			continue
+		}
+
+		name := path[strings.LastIndex(path, "/")+1:]
+		artifacts = append(artifacts, Artifact{
+			Path:         path,
+			Name:         name,
+			Version:      pathVersion,
+			Size:         0,
+			LastModified: time.Time{},
+			ContentType:  ContentTypeForName(name),
+			ETag:         "",
+			Checksums:    nil,
+		})
+	}
+
+	return artifacts
+}
@@ -0,0 +1,168 @@
+package providers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+)
+
+func TestTemplateLayout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		template        string
+		path            string
+		expectedPrefix  string
+		expectedVersion string
+	}{
+		{
+			"releases/{module}/{version}/{artifact}_{version}_{os}_{arch}.tar.gz",
+			"releases/fe/1.2.3/app1_1.2.3_linux_amd64.tar.gz",
+			"releases/fe/",
+			"1.2.3",
+		},
+		{
+			"releases/{module}/{version}/{artifact}_{version}_{os}_{arch}.tar.gz",
+			"releases/fe/1.2.3/app1_1.2.4_linux_amd64.tar.gz",
+			"releases/fe/",
+			"",
+		},
+		{
+			"releases/{module}/{version}/{artifact}_{version}_{os}_{arch}.tar.gz",
+			"releases/fe/1.2.3/other_1.2.3_linux_amd64.tar.gz",
+			"releases/fe/",
+			"",
+		},
+		{"{artifact}/v{version}/", "app1/v2.0.0/", "app1/", "2.0.0"},
+		{"{artifact}/v{version}/", "app1/v2.0.0/bin/app", "app1/", "2.0.0"},
+		{"{artifact}/v{version}/", "app1/2.0.0/", "app1/", ""},
+		{"{module}/{artifact}/{artifact}-{version}.zip", "fe/app1/app1-1.0.0-beta.1.zip", "fe/app1/", "1.0.0-beta.1"},
+		{"{module}/{artifact}/{artifact}-{version}.zip", "fe/app1/app1-1.0.0.zip.sha256", "fe/app1/", ""},
+		{"{module}/{artifact}-{version}.zip", "fe/app1-tools-1.0.0.zip", "fe/", "tools-1.0.0"},
+		{"{module}/{version}/{artifact}-{os}-{version}.zip", "fe/1.0/app1-linux-x-1.0.zip", "fe/", "1.0"},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.template+"/"+testCase.path, func(t *testing.T) {
+			t.Parallel()
+
+			layout, err := providers.NewTemplateLayout(testCase.template)
+			if err != nil {
+				t.Fatalf("NewTemplateLayout(%q) returned an error: %v", testCase.template, err)
+			}
+
+			if got := layout.Prefix("fe", "app1"); got != testCase.expectedPrefix {
+				t.Errorf("Prefix() = %q; want %q", got, testCase.expectedPrefix)
+			}
+
+			got, _ := layout.Match("fe", "app1", testCase.path)
+			if got != testCase.expectedVersion {
+				t.Errorf("Match(%q) = %q; want %q", testCase.path, got, testCase.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestTemplateLayoutHyphenatedArtifact(t *testing.T) {
+	t.Parallel()
+
+	layout, err := providers.NewTemplateLayout("{module}/{artifact}/{artifact}-{version}.zip")
+	if err != nil {
+		t.Fatalf("NewTemplateLayout returned an error: %v", err)
+	}
+
+	for artifact, expected := range map[string]string{"my-app": "1.0.0-rc.1", "my": "", "app": ""} {
+		if got, _ := layout.Match("fe", artifact, "fe/my-app/my-app-1.0.0-rc.1.zip"); got != expected {
+			t.Errorf("Match for artifact %s = %q; want %q", artifact, got, expected)
+		}
+	}
+}
+
+func TestTemplateLayoutDirectories(t *testing.T) {
+	t.Parallel()
+
+	// "-" marks directories that cannot be listed.
+	tests := []struct {
+		template        string
+		modulesPrefix   string
+		artifactsPrefix string
+	}{
+		{"{module}/{artifact}/{artifact}-{version}.zip", "", "fe/"},
+		{"releases/{module}/{artifact}/v{version}/", "releases/", "releases/fe/"},
+		{"releases/{module}/{version}/{artifact}_{version}.tar.gz", "releases/", "-"},
+		{"{artifact}/v{version}/", "-", ""},
+		{"builds-{module}/{artifact}/{version}.zip", "-", "-"},
+	}
+
+	for _, testCase := range tests {
+		layout, err := providers.NewTemplateLayout(testCase.template)
+		if err != nil {
+			t.Fatalf("NewTemplateLayout(%q) returned an error: %v", testCase.template, err)
+		}
+
+		if got, ok := layout.ModulesPrefix(); (ok && got != testCase.modulesPrefix) || ok != (testCase.modulesPrefix != "-") {
+			t.Errorf("%s: ModulesPrefix() = %q, %t; want %q", testCase.template, got, ok, testCase.modulesPrefix)
+		}
+
+		if got, ok := layout.ArtifactsPrefix("fe"); (ok && got != testCase.artifactsPrefix) ||
+			ok != (testCase.artifactsPrefix != "-") {
+			t.Errorf("%s: ArtifactsPrefix() = %q, %t; want %q", testCase.template, got, ok, testCase.artifactsPrefix)
+		}
+	}
+}
+
+func TestRegexLayout(t *testing.T) {
+	t.Parallel()
+
+	layout, err := providers.NewRegexLayout(`^builds/(?P<module>[^/]+)/(?P<artifact>[^/]+)-(?P<version>\d+\.\d+\.\d+)\.jar$`)
+	if err != nil {
+		t.Fatalf("NewRegexLayout returned an error: %v", err)
+	}
+
+	if got := layout.Prefix("fe", "app1"); got != "builds/fe/" {
+		t.Errorf("Prefix() = %q; want %q", got, "builds/fe/")
+	}
+
+	if got := layout.Path("fe", "app1", "1.0.0", "app1-1.0.0.jar"); got != "builds/fe/app1-1.0.0.jar" {
+		t.Errorf("Path() = %q; want %q", got, "builds/fe/app1-1.0.0.jar")
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"builds/fe/app1-1.0.0.jar", "1.0.0"},
+		{"builds/be/app1-1.0.0.jar", ""},
+		{"builds/fe/app2-1.0.0.jar", ""},
+		{"builds/fe/app1-1.0.jar", ""},
+		{"old/builds/fe/app1-1.0.0.jar", ""},
+	}
+
+	for _, testCase := range tests {
+		if got, _ := layout.Match("fe", "app1", testCase.path); got != testCase.expected {
+			t.Errorf("Match(%q) = %q; want %q", testCase.path, got, testCase.expected)
+		}
+	}
+}
+
+func TestNewLayoutInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		template   string
+		expression string
+	}{
+		{"{module}/{artifact}/latest.tar.gz", ""},
+		{"/abs/{version}", ""},
+		{"", "builds/(?P<v>.*)"},
+		{"", "builds/("},
+		{"{version}", "(?P<version>.*)"},
+	}
+
+	for _, testCase := range tests {
+		if _, err := providers.NewLayout(testCase.template, testCase.expression); !errors.Is(err, providers.ErrInvalidLayout) {
+			t.Errorf("NewLayout(%q, %q) returned %v; want %v", testCase.template, testCase.expression, err, providers.ErrInvalidLayout)
+		}
+	}
+}
@@ -1,41 +1,478 @@
 package providers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	localDirPermissions = 0o755
+	// uploadTempPrefix marks in-flight uploads, which are hidden from listings.
+	uploadTempPrefix = ".upload-"
 )
 
 type LocalProvider struct {
 	basePath string
+	layout   Layout
+	observer CallObserver
+
+	// checksums caches the checksums of files by path; entries are bounded by the files in storage.
+	checksumsMutex sync.Mutex
+	checksums      map[string]localChecksum
 }
 
-func NewLocalProvider(basePath string) *LocalProvider {
-	return &LocalProvider{basePath: basePath}
+// localChecksum is valid while the file keeps its size and modification time.
+type localChecksum struct {
+	size    int64
+	modTime time.Time
+	sha256  string
 }
 
-func (p *LocalProvider) GetVersions(moduleName, artifactName string) ([]string, error) {
-	path := filepath.Join(p.basePath, moduleName, artifactName)
-	entries, err := os.ReadDir(path)
+func NewLocalProvider(basePath string, layout Layout) *LocalProvider {
+	return &LocalProvider{
+		basePath:       basePath,
+		layout:         layout,
+		observer:       nil,
+		checksumsMutex: sync.Mutex{},
+		checksums:      map[string]localChecksum{},
+	}
+}
+
+func (p *LocalProvider) SetObserver(observer CallObserver) {
+	p.observer = observer
+}
+
+func (p *LocalProvider) GetVersions(ctx context.Context, moduleName, artifactName string) ([]string, error) {
+	for _, elem := range []string{moduleName, artifactName} {
+		if err := ValidatePath(elem); err != nil {
+			return nil, err
+		}
+	}
+
+	layout := layoutOrDefault(p.layout)
+
+	paths, err := p.listPaths(ctx, layout.Prefix(moduleName, artifactName), layout.Recursive())
+	if err != nil {
+		return nil, err
+	}
+
+	return matchVersions(layout, moduleName, artifactName, paths), nil
+}
+
+func (p *LocalProvider) ListArtifacts(ctx context.Context, moduleName, artifactName,
+	version string) ([]Artifact, error) {
+	for _, elem := range []string{moduleName, artifactName} {
+		if err := ValidatePath(elem); err != nil {
+			return nil, err
+		}
+	}
+
+	layout := layoutOrDefault(p.layout)
+
+	paths, err := p.listPaths(ctx, layout.Prefix(moduleName, artifactName), layout.Recursive())
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := matchArtifacts(layout, moduleName, artifactName, version, paths)
 
+	for index := range artifacts {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("failed to describe files: %w", err)
+		}
+
+		if _, err := p.describe(&artifacts[index]); err != nil {
+			return nil, err
+		}
+	}
+
+	return artifacts, nil
+}
+
+// Checksums reads the file of artifact once per size and modification time.
+func (p *LocalProvider) Checksums(ctx context.Context, artifact Artifact) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to checksum file: %w", err)
+	}
+
+	path, err := SafeJoin(p.basePath, artifact.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
 	if err != nil {
+		return nil, localError("failed to stat file", ErrFileNotFound, err)
+	}
+
+	p.checksumsMutex.Lock()
+	cached, ok := p.checksums[artifact.Path]
+	p.checksumsMutex.Unlock()
+
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return map[string]string{"sha256": cached.sha256}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, localError("failed to open file", ErrFileNotFound, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, localError("failed to checksum file", nil, err)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	p.cacheChecksum(artifact.Path, info, sum)
+
+	return map[string]string{"sha256": sum}, nil
+}
+
+func (p *LocalProvider) cacheChecksum(path string, info fs.FileInfo, sum string) {
+	p.checksumsMutex.Lock()
+	defer p.checksumsMutex.Unlock()
+
+	p.checksums[path] = localChecksum{size: info.Size(), modTime: info.ModTime(), sha256: sum}
+}
+
+func (p *LocalProvider) ListFiles(ctx context.Context, moduleName, artifactName string) ([]string, error) {
+	path, err := SafeJoin(p.basePath, moduleName, artifactName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	var versions []string
+	started := time.Now()
+	entries, err := os.ReadDir(path)
+	p.observer.observe("ReadDir", started, err)
+
+	if err != nil {
+		return nil, localError("failed to read directory", ErrFileNotFound, err)
+	}
+
+	var files []string
 
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
-		filename := entry.Name()
-		version := ExtractVersionFromFilename(filename, artifactName)
+		files = append(files, entry.Name())
+	}
 
-		if version != "" {
-			versions = append(versions, version)
+	return files, nil
+}
+
+func (p *LocalProvider) GetFile(ctx context.Context, moduleName, artifactName,
+	filename string) (io.ReadCloser, error) {
+	for _, elem := range []string{moduleName, artifactName, filename} {
+		if err := ValidatePath(elem); err != nil {
+			return nil, err
 		}
 	}
 
-	return versions, nil
+	return p.Open(ctx, moduleName+"/"+artifactName+"/"+filename)
+}
+
+// Open returns an *os.File so callers can seek for range requests.
+func (p *LocalProvider) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	filename, err := SafeJoin(p.basePath, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	started := time.Now()
+	file, err := os.Open(filename)
+	p.observer.observe("Open", started, err)
+
+	if err != nil {
+		return nil, localError("failed to open file", ErrFileNotFound, err)
+	}
+
+	return file, nil
+}
+
+// Delete removes the file at path along with its cached checksum; directories left empty are kept.
+func (p *LocalProvider) Delete(ctx context.Context, _, _, path string) error {
+	filename, err := SafeJoin(p.basePath, path)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	started := time.Now()
+	err = os.Remove(filename)
+	p.observer.observe("Remove", started, err)
+
+	p.checksumsMutex.Lock()
+	delete(p.checksums, path)
+	p.checksumsMutex.Unlock()
+
+	if err != nil {
+		return localError("failed to delete file", ErrFileNotFound, err)
+	}
+
+	return nil
+}
+
+func (p *LocalProvider) ListModules(ctx context.Context, page Page) (Listing, error) {
+	prefix, err := directoryPrefix(p.layout, "")
+	if err != nil {
+		return Listing{}, err
+	}
+
+	return p.listDirectories(ctx, prefix, page)
+}
+
+func (p *LocalProvider) ListArtifactNames(ctx context.Context, moduleName string, page Page) (Listing, error) {
+	prefix, err := directoryPrefix(p.layout, moduleName)
+	if err != nil {
+		return Listing{}, err
+	}
+
+	return p.listDirectories(ctx, prefix, page)
+}
+
+// listDirectories pages through the directories below prefix; the cursor is the last name returned.
+func (p *LocalProvider) listDirectories(ctx context.Context, prefix string, page Page) (Listing, error) {
+	if err := ctx.Err(); err != nil {
+		return Listing{}, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	started := time.Now()
+	entries, err := os.ReadDir(filepath.Join(p.basePath, filepath.FromSlash(prefix)))
+	p.observer.observe("ReadDir", started, err)
+
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Listing{Names: []string{}, Next: ""}, nil
+		}
+
+		return Listing{}, localError("failed to read directory", nil, err)
+	}
+
+	names := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && entry.Name() > page.Cursor {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return pageOf(names, page.Limit), nil
+}
+
+// Publish writes to a temporary file first and hard links it into place, so readers never see a partial
+// file and an existing file is never replaced.
+func (p *LocalProvider) Publish(ctx context.Context, moduleName, artifactName, version, filename string,
+	body io.Reader) (Artifact, error) {
+	path, err := publishPath(layoutOrDefault(p.layout), moduleName, artifactName, version, filename)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	target, err := SafeJoin(p.basePath, path)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), localDirPermissions); err != nil {
+		return Artifact{}, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(target), uploadTempPrefix+"*")
+	if err != nil {
+		return Artifact{}, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	hash := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(temp, hash), body)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		// An abandoned upload must not be published even if the body happened to be complete.
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		return Artifact{}, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	started := time.Now()
+	err = os.Link(temp.Name(), target)
+	p.observer.observe("Link", started, err)
+
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return Artifact{}, fmt.Errorf("%w: %s", ErrFileExists, path)
+		}
+
+		return Artifact{}, fmt.Errorf("failed to publish file: %w", err)
+	}
+
+	artifact := Artifact{
+		Path:         path,
+		Name:         filename,
+		Version:      version,
+		Size:         size,
+		LastModified: time.Time{},
+		ContentType:  ContentTypeForName(filename),
+		ETag:         "",
+		Checksums:    map[string]string{"sha256": hex.EncodeToString(hash.Sum(nil))},
+	}
+
+	info, err := p.describe(&artifact)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	p.cacheChecksum(path, info, artifact.Checksums["sha256"])
+
+	return artifact, nil
+}
+
+// Check verifies the base path is a readable directory.
+func (p *LocalProvider) Check(_ context.Context) error {
+	info, err := os.Stat(p.basePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat base path: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%w: base path %s is not a directory", ErrInvalidPath, p.basePath)
+	}
+
+	directory, err := os.Open(p.basePath)
+	if err != nil {
+		return fmt.Errorf("failed to open base path: %w", err)
+	}
+	defer directory.Close()
+
+	if _, err := directory.ReadDir(1); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read base path: %w", err)
+	}
+
+	return nil
+}
+
+// describe fills in file metadata; checksums are only computed when asked for since they read the file.
+func (p *LocalProvider) describe(artifact *Artifact) (fs.FileInfo, error) {
+	info, err := os.Stat(filepath.Join(p.basePath, filepath.FromSlash(artifact.Path)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	artifact.Size = info.Size()
+	artifact.LastModified = info.ModTime().UTC()
+	// Like the ETags of most web servers, it changes whenever the file is rewritten.
+	artifact.ETag = fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+
+	return info, nil
+}
+
+// listPaths returns slash separated paths relative to the base path; directories end with a slash
+// so layouts can encode versions as directory names.
+func (p *LocalProvider) listPaths(ctx context.Context, prefix string, recursive bool) ([]string, error) {
+	root := filepath.Join(p.basePath, filepath.FromSlash(prefix))
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	if !recursive {
+		started := time.Now()
+		entries, err := os.ReadDir(root)
+		p.observer.observe("ReadDir", started, err)
+
+		if err != nil {
+			return nil, localError("failed to read directory", ErrArtifactNotFound, err)
+		}
+
+		paths := make([]string, 0, len(entries))
+
+		for _, entry := range entries {
+			if !entry.IsDir() && !strings.HasPrefix(entry.Name(), uploadTempPrefix) {
+				paths = append(paths, prefix+entry.Name())
+			}
+		}
+
+		return paths, nil
+	}
+
+	var paths []string
+
+	started := time.Now()
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if path == root || strings.HasPrefix(entry.Name(), uploadTempPrefix) {
+			return nil
+		}
+
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve relative path: %w", err)
+		}
+
+		relative = prefix + filepath.ToSlash(relative)
+		if entry.IsDir() {
+			relative += "/"
+		}
+
+		paths = append(paths, relative)
+
+		return nil
+	})
+	p.observer.observe("WalkDir", started, err)
+
+	if err != nil {
+		return nil, localError("failed to read directory", ErrArtifactNotFound, err)
+	}
+
+	return paths, nil
+}
+
+// localError tells a missing path, reported as notFound, from a failing file system; cancellation is
+// passed through unclassified.
+func localError(message string, notFound, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && notFound != nil:
+		return fmt.Errorf("%s: %w: %w", message, notFound, err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%s: %w", message, err)
+	default:
+		return fmt.Errorf("%s: %w: %w", message, ErrProviderUnavailable, err)
+	}
 }
@@ -1,9 +1,14 @@
 package providers_test
 
 import (
+	"context"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mauhlik/go-index/internal/go-index/providers"
 )
@@ -33,9 +38,9 @@ func TestLocalProviderGetVersions(t *testing.T) {
 		}
 	}
 
-	provider := providers.NewLocalProvider(tempDir)
+	provider := providers.NewLocalProvider(tempDir, providers.DefaultLayout{})
 
-	gotVersions, err := provider.GetVersions(moduleName, artifactName)
+	gotVersions, err := provider.GetVersions(context.Background(), moduleName, artifactName)
 	if err != nil {
 		t.Fatalf("GetVersions returned an error: %v", err)
 	}
@@ -50,4 +55,356 @@ func TestLocalProviderGetVersions(t *testing.T) {
 			t.Errorf("GetVersions returned version %q; want %q", version, expectedVersions[i])
 		}
 	}
+
+	_, err = provider.GetVersions(context.Background(), moduleName, "missing")
+	if !errors.Is(err, providers.ErrArtifactNotFound) {
+		t.Errorf("GetVersions of a missing artifact returned %v; want %v", err, providers.ErrArtifactNotFound)
+	}
+}
+
+func TestLocalProviderGetFile(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+
+	filename := filepath.Join(tempDir, "fe", "app1", "app1-1.0.0.txt")
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	if err := os.WriteFile(filename, []byte("content"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	provider := providers.NewLocalProvider(tempDir, providers.DefaultLayout{})
+
+	body, err := provider.GetFile(context.Background(), "fe", "app1", "app1-1.0.0.txt")
+	if err != nil {
+		t.Fatalf("GetFile returned an error: %v", err)
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	if string(content) != "content" {
+		t.Errorf("GetFile returned %q; want %q", content, "content")
+	}
+
+	if _, err := provider.GetFile(context.Background(), "fe", "app1", "app1-2.0.0.txt"); !errors.Is(err, providers.ErrFileNotFound) {
+		t.Errorf("GetFile returned %v for a missing file; want %v", err, providers.ErrFileNotFound)
+	}
+
+	if _, err := provider.GetFile(context.Background(), "fe", "app1", "../../secret"); !errors.Is(err, providers.ErrInvalidPath) {
+		t.Errorf("GetFile returned %v for a traversal path; want %v", err, providers.ErrInvalidPath)
+	}
+
+	if _, err := provider.ListFiles(context.Background(), "fe", "missing"); !errors.Is(err, providers.ErrFileNotFound) {
+		t.Errorf("ListFiles returned %v for a missing directory; want %v", err, providers.ErrFileNotFound)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := provider.GetFile(canceled, "fe", "app1", "app1-1.0.0.txt"); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetFile returned %v for a canceled context; want %v", err, context.Canceled)
+	}
+}
+
+func TestLocalProviderGetVersionsWithLayout(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+
+	paths := []string{
+		"releases/fe/1.0.0/app1_1.0.0_linux_amd64.tar.gz",
+		"releases/fe/1.0.0/app1_1.0.0_darwin_arm64.tar.gz",
+		"releases/fe/1.1.0/app1_1.1.0_linux_amd64.tar.gz",
+		"releases/fe/1.1.0/app2_1.1.0_linux_amd64.tar.gz",
+		"releases/fe/2.0.0/app2_2.0.0_linux_amd64.tar.gz",
+	}
+
+	for _, path := range paths {
+		filename := filepath.Join(tempDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+
+		if err := os.WriteFile(filename, nil, 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(tempDir, "app1", "v3.0.0"), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	tests := []struct {
+		template string
+		expected []string
+	}{
+		{"releases/{module}/{version}/{artifact}_{version}_{os}_{arch}.tar.gz", []string{"1.0.0", "1.1.0"}},
+		{"{artifact}/v{version}/", []string{"3.0.0"}},
+	}
+
+	for _, testCase := range tests {
+		layout, err := providers.NewTemplateLayout(testCase.template)
+		if err != nil {
+			t.Fatalf("NewTemplateLayout returned an error: %v", err)
+		}
+
+		gotVersions, err := providers.NewLocalProvider(tempDir, layout).GetVersions(context.Background(), "fe", "app1")
+		if err != nil {
+			t.Fatalf("GetVersions returned an error: %v", err)
+		}
+
+		if strings.Join(gotVersions, ",") != strings.Join(testCase.expected, ",") {
+			t.Errorf("GetVersions with layout %q returned %v; want %v", testCase.template, gotVersions, testCase.expected)
+		}
+	}
+}
+
+func TestLocalProviderListArtifacts(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+
+	for _, name := range []string{"app1-1.0.0.tar.gz", "app1-1.0.0.txt", "app1-2.0.0.tar.gz"} {
+		filename := filepath.Join(tempDir, "fe", "app1", name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte("hello"), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	provider := providers.NewLocalProvider(tempDir, providers.DefaultLayout{})
+
+	artifacts, err := provider.ListArtifacts(context.Background(), "fe", "app1", "1.0.0")
+	if err != nil {
+		t.Fatalf("ListArtifacts returned an error: %v", err)
+	}
+
+	if len(artifacts) != 2 {
+		t.Fatalf("ListArtifacts returned %d artifacts; want 2", len(artifacts))
+	}
+
+	artifact := artifacts[0]
+	if artifact.Path != "fe/app1/app1-1.0.0.tar.gz" || artifact.Name != "app1-1.0.0.tar.gz" || artifact.Version != "1.0.0" {
+		t.Errorf("ListArtifacts returned %+v; want fe/app1/app1-1.0.0.tar.gz", artifact)
+	}
+
+	if artifact.Size != 5 || artifact.LastModified.IsZero() {
+		t.Errorf("ListArtifacts returned size %d, modified %v; want 5 and a timestamp", artifact.Size, artifact.LastModified)
+	}
+
+	if artifact.ContentType != "application/gzip" {
+		t.Errorf("ListArtifacts returned content type %q; want %q", artifact.ContentType, "application/gzip")
+	}
+
+	if artifact.Checksums != nil {
+		t.Errorf("ListArtifacts returned checksums %v; want none, they are computed on request", artifact.Checksums)
+	}
+
+	all, err := provider.ListArtifacts(context.Background(), "fe", "app1", "")
+	if err != nil {
+		t.Fatalf("ListArtifacts returned an error: %v", err)
+	}
+
+	if len(all) != 3 || all[0].Checksums != nil {
+		t.Errorf("ListArtifacts returned %+v; want 3 artifacts without checksums", all)
+	}
+}
+
+func TestLocalProviderChecksums(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+
+	filename := filepath.Join(tempDir, "fe", "app1", "app1-1.0.0.txt")
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	if err := os.WriteFile(filename, []byte("hello"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	provider := providers.NewLocalProvider(tempDir, providers.DefaultLayout{})
+	artifact := providers.Artifact{Path: "fe/app1/app1-1.0.0.txt"} //nolint:exhaustruct
+
+	checksums, err := provider.Checksums(context.Background(), artifact)
+	if err != nil {
+		t.Fatalf("Checksums returned an error: %v", err)
+	}
+
+	expectedChecksum := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if checksums["sha256"] != expectedChecksum {
+		t.Errorf("Checksums returned sha256 %q; want %q", checksums["sha256"], expectedChecksum)
+	}
+
+	// A rewritten file has a new modification time, so the cached checksum no longer applies.
+	if err := os.WriteFile(filename, []byte("world"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	modified := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filename, modified, modified); err != nil {
+		t.Fatalf("Failed to change file times: %v", err)
+	}
+
+	checksums, err = provider.Checksums(context.Background(), artifact)
+	if err != nil {
+		t.Fatalf("Checksums returned an error: %v", err)
+	}
+
+	expectedChecksum = "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7"
+	if checksums["sha256"] != expectedChecksum {
+		t.Errorf("Checksums of the rewritten file returned sha256 %q; want %q", checksums["sha256"], expectedChecksum)
+	}
+}
+
+func TestLocalProviderPublish(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+
+	provider := providers.NewLocalProvider(tempDir, providers.DefaultLayout{})
+
+	artifact, err := provider.Publish(context.Background(), "fe", "app1", "1.0.0", "app1-1.0.0.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatalf("Publish returned an error: %v", err)
+	}
+
+	if artifact.Path != "fe/app1/app1-1.0.0.txt" || artifact.Size != int64(len("content")) ||
+		artifact.Checksums["sha256"] == "" {
+		t.Errorf("Publish returned %+v; want fe/app1/app1-1.0.0.txt with size and checksum", artifact)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "fe", "app1", "app1-1.0.0.txt"))
+	if err != nil || string(content) != "content" {
+		t.Errorf("Published file contains %q (%v); want %q", content, err, "content")
+	}
+
+	_, err = provider.Publish(context.Background(), "fe", "app1", "1.0.0", "app1-1.0.0.txt", strings.NewReader("other"))
+	if !errors.Is(err, providers.ErrFileExists) {
+		t.Errorf("Publish returned %v; want %v", err, providers.ErrFileExists)
+	}
+
+	_, err = provider.Publish(context.Background(), "fe", "app1", "1.0.0", "app1-2.0.0.txt", strings.NewReader("content"))
+	if !errors.Is(err, providers.ErrLayoutMismatch) {
+		t.Errorf("Publish returned %v; want %v", err, providers.ErrLayoutMismatch)
+	}
+
+	_, err = provider.Publish(context.Background(), "fe", "app1", "1.0.0", "../app1-1.0.0.txt", strings.NewReader("content"))
+	if !errors.Is(err, providers.ErrInvalidPath) {
+		t.Errorf("Publish returned %v; want %v", err, providers.ErrInvalidPath)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(tempDir, "fe", "app1"))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Directory has %d entries (%v); want only the published file", len(entries), err)
+	}
+}
+
+func TestLocalProviderPublishWithLayout(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+
+	layout, err := providers.NewTemplateLayout("releases/{module}/{version}/{artifact}_{version}_{os}.tar.gz")
+	if err != nil {
+		t.Fatalf("NewTemplateLayout returned an error: %v", err)
+	}
+
+	provider := providers.NewLocalProvider(tempDir, layout)
+
+	artifact, err := provider.Publish(context.Background(), "fe", "app1", "1.2.0", "app1_1.2.0_linux.tar.gz", strings.NewReader("content"))
+	if err != nil {
+		t.Fatalf("Publish returned an error: %v", err)
+	}
+
+	if artifact.Path != "releases/fe/1.2.0/app1_1.2.0_linux.tar.gz" {
+		t.Errorf("Publish stored the file at %q; want %q", artifact.Path, "releases/fe/1.2.0/app1_1.2.0_linux.tar.gz")
+	}
+
+	versions, err := provider.GetVersions(context.Background(), "fe", "app1")
+	if err != nil || len(versions) != 1 || versions[0] != "1.2.0" {
+		t.Errorf("GetVersions returned %v (%v); want [1.2.0]", versions, err)
+	}
+
+	_, err = provider.Publish(context.Background(), "fe", "app1", "1.2.0", "app1-1.2.0.zip", strings.NewReader("content"))
+	if !errors.Is(err, providers.ErrLayoutMismatch) {
+		t.Errorf("Publish returned %v; want %v", err, providers.ErrLayoutMismatch)
+	}
+}
+
+func TestLocalProviderCheck(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+
+	if err := providers.NewLocalProvider(tempDir, nil).Check(context.Background()); err != nil {
+		t.Errorf("Check returned an error: %v", err)
+	}
+
+	if err := providers.NewLocalProvider(filepath.Join(tempDir, "missing"), nil).Check(context.Background()); err == nil {
+		t.Error("Check returned no error for a missing directory")
+	}
+}
+
+func TestLocalProviderListModules(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+
+	for _, path := range []string{"fe/app1/app1-1.0.0.txt", "fe/app2/app2-1.0.0.txt", "be/api/api-1.0.0.txt",
+		"ops/.cache/data", "README.md"} {
+		filename := filepath.Join(tempDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte("hello"), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	provider := providers.NewLocalProvider(tempDir, nil)
+	ctx := context.Background()
+
+	first, err := provider.ListModules(ctx, providers.Page{Limit: 2, Cursor: ""})
+	if err != nil || strings.Join(first.Names, ",") != "be,fe" || first.Next != "fe" {
+		t.Fatalf("ListModules returned %+v, %v; want [be fe] and a next page", first, err)
+	}
+
+	second, err := provider.ListModules(ctx, providers.Page{Limit: 2, Cursor: first.Next})
+	if err != nil || strings.Join(second.Names, ",") != "ops" || second.Next != "" {
+		t.Errorf("ListModules returned %+v, %v; want the last page [ops]", second, err)
+	}
+
+	artifacts, err := provider.ListArtifactNames(ctx, "fe", providers.Page{Limit: 10, Cursor: ""})
+	if err != nil || strings.Join(artifacts.Names, ",") != "app1,app2" {
+		t.Errorf("ListArtifactNames returned %+v, %v; want [app1 app2]", artifacts, err)
+	}
+
+	hidden, err := provider.ListArtifactNames(ctx, "ops", providers.Page{Limit: 10, Cursor: ""})
+	if err != nil || len(hidden.Names) != 0 {
+		t.Errorf("ListArtifactNames returned %+v, %v; want hidden directories skipped", hidden, err)
+	}
+
+	missing, err := provider.ListArtifactNames(ctx, "missing", providers.Page{Limit: 10, Cursor: ""})
+	if err != nil || len(missing.Names) != 0 {
+		t.Errorf("ListArtifactNames returned %+v, %v for a missing module; want no names", missing, err)
+	}
+
+	if _, err := provider.ListArtifactNames(ctx, "..", providers.Page{Limit: 10, Cursor: ""}); !errors.Is(err, providers.ErrInvalidPath) {
+		t.Errorf("ListArtifactNames returned %v; want %v", err, providers.ErrInvalidPath)
+	}
+
+	layout, err := providers.NewRegexLayout(`builds/(?P<artifact>[^/]+)-(?P<version>[0-9.]+)\.jar`)
+	if err != nil {
+		t.Fatalf("NewRegexLayout returned an error: %v", err)
+	}
+
+	_, err = providers.NewLocalProvider(tempDir, layout).ListModules(ctx, providers.Page{Limit: 10, Cursor: ""})
+	if !errors.Is(err, providers.ErrListingUnsupported) {
+		t.Errorf("ListModules returned %v for a regex layout; want %v", err, providers.ErrListingUnsupported)
+	}
 }
@@ -1,5 +1,104 @@
 package providers
 
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrFileNotFound     = errors.New("file not found")
+	ErrArtifactNotFound = errors.New("artifact not found")
+	// ErrProviderUnavailable wraps failures of the storage backend itself, as opposed to missing files.
+	ErrProviderUnavailable = errors.New("provider unavailable")
+	ErrPresignUnsupported  = errors.New("provider does not support presigned URLs")
+	ErrFileExists          = errors.New("file already exists")
+	ErrPublishUnsupported  = errors.New("provider does not support publishing")
+	ErrDeleteUnsupported   = errors.New("provider does not support deleting")
+	ErrListingUnsupported  = errors.New("layout does not keep modules and artifacts in directories of their own")
+)
+
+type Artifact struct {
+	Path         string    `json:"path"`
+	Name         string    `json:"name"`
+	Version      string    `json:"version"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	ContentType  string    `json:"contentType"`
+	// ETag changes with the stored content and serves HTTP caching; it is not a checksum of the file.
+	ETag      string            `json:"etag,omitempty"`
+	Checksums map[string]string `json:"checksums,omitempty"`
+}
+
 type Provider interface {
-	GetVersions(moduleName, artifactName string) ([]string, error)
+	GetVersions(ctx context.Context, moduleName, artifactName string) ([]string, error)
+	// ListArtifacts returns the files of every version, or of a single version when version is not empty.
+	ListArtifacts(ctx context.Context, moduleName, artifactName, version string) ([]Artifact, error)
+	ListFiles(ctx context.Context, moduleName, artifactName string) ([]string, error)
+	GetFile(ctx context.Context, moduleName, artifactName, filename string) (io.ReadCloser, error)
+	// Open reads a file by its storage path as reported in Artifact.Path. ctx bounds opening the file;
+	// once it returns, reading the body is bounded by the caller closing it.
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// Presigner is implemented by providers that can hand out short-lived direct download URLs.
+type Presigner interface {
+	PresignGet(ctx context.Context, path string, expires time.Duration) (string, error)
+}
+
+// Checksummer is implemented by providers whose checksums cost a file read or a request per file. Their
+// ListArtifacts leaves Checksums out so listings, such as the one behind every download, stay cheap.
+type Checksummer interface {
+	Checksums(ctx context.Context, artifact Artifact) (map[string]string, error)
+}
+
+// Publisher is implemented by writable providers. The storage path is derived from the layout and
+// existing files are never overwritten.
+type Publisher interface {
+	Publish(ctx context.Context, moduleName, artifactName, version, filename string, body io.Reader) (Artifact, error)
+}
+
+// Deleter is implemented by providers that can remove a file by its storage path as reported in
+// Artifact.Path; moduleName and artifactName name the listing the file belongs to.
+type Deleter interface {
+	Delete(ctx context.Context, moduleName, artifactName, path string) error
+}
+
+// Page selects part of a listing: at most Limit names following the opaque Cursor of the previous page.
+type Page struct {
+	Limit  int
+	Cursor string
+}
+
+// Listing is one page of names in lexical order; Next is the cursor of the following page, empty on the last.
+type Listing struct {
+	Names []string
+	Next  string
+}
+
+// Lister is implemented by providers that can enumerate modules and the artifacts of a module. Listing a
+// module that does not exist returns no names rather than an error.
+type Lister interface {
+	ListModules(ctx context.Context, page Page) (Listing, error)
+	ListArtifactNames(ctx context.Context, moduleName string, page Page) (Listing, error)
+}
+
+// CallObserver is told about every backend call, such as one ListObjectsV2 page or a directory read.
+type CallObserver func(operation string, duration time.Duration, err error)
+
+// Observable is implemented by providers that report their backend calls.
+type Observable interface {
+	SetObserver(observer CallObserver)
+}
+
+func (o CallObserver) observe(operation string, started time.Time, err error) {
+	if o != nil {
+		o(operation, time.Since(started), err)
+	}
+}
+
+// Checker is implemented by providers that can verify their backend is reachable.
+type Checker interface {
+	Check(ctx context.Context) error
 }
@@ -1,52 +1,272 @@
 package providers
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/smithy-go"
 	"github.com/sirupsen/logrus"
 )
 
 type S3Client interface {
 	ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input,
 		opts ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	GetObject(ctx context.Context, input *s3.GetObjectInput,
+		opts ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, input *s3.PutObjectInput,
+		opts ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	CreateMultipartUpload(ctx context.Context, input *s3.CreateMultipartUploadInput,
+		opts ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, input *s3.UploadPartInput,
+		opts ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+	CompleteMultipartUpload(ctx context.Context, input *s3.CompleteMultipartUploadInput,
+		opts ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput,
+		opts ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+	HeadBucket(ctx context.Context, input *s3.HeadBucketInput,
+		opts ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	HeadObject(ctx context.Context, input *s3.HeadObjectInput,
+		opts ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	DeleteObject(ctx context.Context, input *s3.DeleteObjectInput,
+		opts ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
 }
 
+type S3PresignClient interface {
+	PresignGetObject(ctx context.Context, input *s3.GetObjectInput,
+		opts ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
+// s3PartSize is both the single PutObject limit and the multipart part size; parts are buffered in memory.
+const s3PartSize = 16 << 20
+
+// partBuffers recycles the part buffers of multipart uploads.
+var partBuffers = sync.Pool{New: func() any {
+	buffer := make([]byte, s3PartSize)
+
+	return &buffer
+}}
+
+var errPreconditionCodes = map[string]bool{"PreconditionFailed": true, "ConditionalRequestConflict": true}
+
 type S3Provider struct {
-	Client S3Client
-	Bucket string
-	logger *logrus.Logger
+	Client    S3Client
+	Presigner S3PresignClient
+	Bucket    string
+	// Prefix is prepended to every key, so the repository can live in a folder of a shared bucket.
+	Prefix   string
+	Layout   Layout
+	logger   *logrus.Logger
+	observer CallObserver
 }
 
-func NewS3Provider(bucket, endpoint, accessKey, secretKey, region string, logger *logrus.Logger) (*S3Provider, error) {
-	logger.Infof("Initialized S3 client endpoint %s region %s", endpoint, region)
+// S3Options configure NewS3Provider. Without AccessKey and SecretKey the default AWS credential chain is
+// used: environment variables, shared config files, web identity (IRSA) and container or instance roles.
+type S3Options struct {
+	Bucket string
+	// Endpoint replaces the AWS endpoint, e.g. for MinIO; empty uses the endpoint of the region.
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Profile selects a profile of the shared config files.
+	Profile string
+	// AssumeRoleARN is assumed through STS with the credentials resolved above.
+	AssumeRoleARN string
+	// UsePathStyle addresses objects as endpoint/bucket/key instead of bucket.endpoint/key.
+	UsePathStyle bool
+	// CABundle is a PEM file of certificate authorities trusted in addition to the system ones.
+	CABundle string
+	Prefix   string
+}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
-		config.WithBaseEndpoint(endpoint),
-	)
+func NewS3Provider(options S3Options, layout Layout, logger *logrus.Logger) (*S3Provider, error) {
+	loadOptions, err := s3LoadOptions(options)
+	if err != nil {
+		return nil, err
+	}
 
+	cfg, err := config.LoadDefaultConfig(context.TODO(), loadOptions...)
 	if err != nil {
 		logger.WithError(err).Error("Failed to load AWS config")
 
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	client := s3.NewFromConfig(cfg)
+	if options.AssumeRoleARN != "" {
+		cfg.Credentials = awsv2.NewCredentialsCache(stscreds.NewAssumeRoleProvider(
+			sts.NewFromConfig(cfg), options.AssumeRoleARN, func(roleOptions *stscreds.AssumeRoleOptions) {
+				roleOptions.RoleSessionName = "go-index"
+			},
+		))
+	}
+
+	client := s3.NewFromConfig(cfg, func(s3Options *s3.Options) {
+		s3Options.UsePathStyle = options.UsePathStyle
+	})
 
-	logger.Infof("Initialized S3 client endpoint %s region %s", endpoint, region)
+	logger.Infof("Initialized S3 client endpoint %s region %s", options.Endpoint, cfg.Region)
+
+	prefix := strings.Trim(options.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
 
-	return &S3Provider{Client: client, Bucket: bucket, logger: logger}, nil
+	return &S3Provider{
+		Client:    client,
+		Presigner: s3.NewPresignClient(client),
+		Bucket:    options.Bucket,
+		Prefix:    prefix,
+		Layout:    layout,
+		logger:    logger,
+		observer:  nil,
+	}, nil
 }
 
-func (p *S3Provider) GetVersions(moduleName, artifactName string) ([]string, error) {
+func s3LoadOptions(options S3Options) ([]func(*config.LoadOptions) error, error) {
+	var loadOptions []func(*config.LoadOptions) error
+
+	if options.Region != "" {
+		loadOptions = append(loadOptions, config.WithRegion(options.Region))
+	}
+
+	if options.Endpoint != "" {
+		loadOptions = append(loadOptions, config.WithBaseEndpoint(options.Endpoint))
+	}
+
+	if options.Profile != "" {
+		loadOptions = append(loadOptions, config.WithSharedConfigProfile(options.Profile))
+	}
+
+	if options.AccessKey != "" || options.SecretKey != "" {
+		loadOptions = append(loadOptions, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(options.AccessKey, options.SecretKey, ""),
+		))
+	}
+
+	if options.CABundle != "" {
+		bundle, err := os.ReadFile(options.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		loadOptions = append(loadOptions, config.WithCustomCABundle(bytes.NewReader(bundle)))
+	}
+
+	return loadOptions, nil
+}
+
+func (p *S3Provider) GetVersions(ctx context.Context, moduleName, artifactName string) ([]string, error) {
+	for _, elem := range []string{moduleName, artifactName} {
+		if err := ValidatePath(elem); err != nil {
+			return nil, err
+		}
+	}
+
+	layout := layoutOrDefault(p.Layout)
+
+	keys, err := p.listKeys(ctx, layout.Prefix(moduleName, artifactName))
+	if err != nil {
+		return nil, err
+	}
+
+	return matchVersions(layout, moduleName, artifactName, keys), nil
+}
+
+func (p *S3Provider) ListArtifacts(ctx context.Context, moduleName, artifactName,
+	version string) ([]Artifact, error) {
+	for _, elem := range []string{moduleName, artifactName} {
+		if err := ValidatePath(elem); err != nil {
+			return nil, err
+		}
+	}
+
+	layout := layoutOrDefault(p.Layout)
+
+	objects, err := p.listObjects(ctx, layout.Prefix(moduleName, artifactName))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(objects))
+	byKey := make(map[string]types.Object, len(objects))
+
+	for _, object := range objects {
+		keys = append(keys, *object.Key)
+		byKey[*object.Key] = object
+	}
+
+	artifacts := matchArtifacts(layout, moduleName, artifactName, version, keys)
+
+	for index := range artifacts {
+		object := byKey[artifacts[index].Path]
+		artifacts[index].Size = aws.Int64Value(object.Size)
+		artifacts[index].LastModified = aws.TimeValue(object.LastModified).UTC()
+
+		artifacts[index].ETag = strings.Trim(aws.StringValue(object.ETag), `"`)
+	}
+
+	return artifacts, nil
+}
+
+func (p *S3Provider) ListFiles(ctx context.Context, moduleName, artifactName string) ([]string, error) {
 	prefix := fmt.Sprintf("%s/%s/", moduleName, artifactName)
+
+	keys, err := p.listKeys(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+
+	for _, key := range keys {
+		filename := strings.TrimPrefix(key, prefix)
+
+		if filename != "" && !strings.Contains(filename, "/") {
+			files = append(files, filename)
+		}
+	}
+
+	return files, nil
+}
+
+func (p *S3Provider) listKeys(ctx context.Context, prefix string) ([]string, error) {
+	objects, err := p.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(objects))
+
+	for _, object := range objects {
+		keys = append(keys, *object.Key)
+	}
+
+	return keys, nil
+}
+
+// listObjects returns the objects under prefix with keys relative to the provider's Prefix.
+func (p *S3Provider) listObjects(ctx context.Context, prefix string) ([]types.Object, error) {
+	prefix = p.Prefix + prefix
 	input := &s3.ListObjectsV2Input{
 		Bucket:                   &p.Bucket,
 		Prefix:                   &prefix,
@@ -61,31 +281,443 @@ func (p *S3Provider) GetVersions(moduleName, artifactName string) ([]string, err
 		StartAfter:               aws.String(""),
 	}
 
-	var versions []string
+	var objects []types.Object
 
 	paginator := s3.NewListObjectsV2Paginator(p.Client, input)
 
 	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(context.TODO())
+		started := time.Now()
+		page, err := paginator.NextPage(ctx)
+		p.observer.observe("ListObjectsV2", started, err)
 
 		if err != nil {
 			p.logger.WithError(err).Error("Failed to list objects")
 
-			return nil, fmt.Errorf("failed to list objects: %w", err)
+			return nil, s3Error("failed to list objects", err)
 		}
 
 		for _, obj := range page.Contents {
-			key := *obj.Key
-			if strings.HasPrefix(key, prefix) {
-				filename := strings.TrimPrefix(key, prefix)
-				version := ExtractVersionFromFilename(filename, artifactName)
-
-				if version != "" {
-					versions = append(versions, version)
-				}
+			if strings.HasPrefix(aws.StringValue(obj.Key), prefix) {
+				obj.Key = aws.String(strings.TrimPrefix(*obj.Key, p.Prefix))
+				objects = append(objects, obj)
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+func (p *S3Provider) ListModules(ctx context.Context, page Page) (Listing, error) {
+	prefix, err := directoryPrefix(p.Layout, "")
+	if err != nil {
+		return Listing{}, err
+	}
+
+	return p.listCommonPrefixes(ctx, prefix, page)
+}
+
+func (p *S3Provider) ListArtifactNames(ctx context.Context, moduleName string, page Page) (Listing, error) {
+	prefix, err := directoryPrefix(p.Layout, moduleName)
+	if err != nil {
+		return Listing{}, err
+	}
+
+	return p.listCommonPrefixes(ctx, prefix, page)
+}
+
+// listCommonPrefixes pages through the "directories" below prefix; the cursor is S3's continuation token.
+// Objects directly below prefix count against MaxKeys too, so pages are requested until limit is reached.
+func (p *S3Provider) listCommonPrefixes(ctx context.Context, prefix string, page Page) (Listing, error) {
+	prefix = p.Prefix + prefix
+	listing := Listing{Names: []string{}, Next: page.Cursor}
+
+	for {
+		input := &s3.ListObjectsV2Input{
+			Bucket:    &p.Bucket,
+			Prefix:    &prefix,
+			Delimiter: aws.String("/"),
+		}
+
+		if listing.Next != "" {
+			input.ContinuationToken = aws.String(listing.Next)
+		}
+
+		if page.Limit > 0 {
+			input.MaxKeys = aws.Int32(int32(min(page.Limit-len(listing.Names), math.MaxInt32))) //nolint:gosec
+		}
+
+		started := time.Now()
+		output, err := p.Client.ListObjectsV2(ctx, input)
+		p.observer.observe("ListObjectsV2", started, err)
+
+		if err != nil {
+			p.logger.WithError(err).Error("Failed to list prefixes")
+
+			return Listing{}, s3Error("failed to list prefixes", err)
+		}
+
+		for _, commonPrefix := range output.CommonPrefixes {
+			name, ok := strings.CutPrefix(aws.StringValue(commonPrefix.Prefix), prefix)
+			if name = strings.TrimSuffix(name, "/"); ok && name != "" && !strings.HasPrefix(name, ".") {
+				listing.Names = append(listing.Names, name)
 			}
 		}
+
+		listing.Next = ""
+		if aws.BoolValue(output.IsTruncated) {
+			listing.Next = aws.StringValue(output.NextContinuationToken)
+		}
+
+		if listing.Next == "" || (page.Limit > 0 && len(listing.Names) >= page.Limit) {
+			return listing, nil
+		}
+	}
+}
+
+func (p *S3Provider) GetFile(ctx context.Context, moduleName, artifactName,
+	filename string) (io.ReadCloser, error) {
+	for _, elem := range []string{moduleName, artifactName, filename} {
+		if err := ValidatePath(elem); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.Open(ctx, fmt.Sprintf("%s/%s/%s", moduleName, artifactName, filename))
+}
+
+// Open only lets ctx cancel the request until the response headers arrive; the body is streamed on a
+// context that ends when it is closed, so a request deadline does not cut off a long download.
+func (p *S3Provider) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ValidatePath(path); err != nil {
+		return nil, err
+	}
+
+	bodyCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+
+	started := time.Now()
+	output, err := p.Client.GetObject(bodyCtx, &s3.GetObjectInput{
+		Bucket: &p.Bucket,
+		Key:    aws.String(p.Prefix + path),
+	})
+	err = objectNotFound(err)
+	p.observer.observe("GetObject", started, err)
+
+	if !stop() || err != nil {
+		cancel()
+
+		if err == nil {
+			output.Body.Close()
+
+			err = ctx.Err()
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrFileNotFound) {
+			return nil, fmt.Errorf("failed to get object %s: %w", path, err)
+		}
+
+		p.logger.WithError(err).Errorf("Failed to get object %s", path)
+
+		return nil, s3Error("failed to get object "+path, err)
+	}
+
+	return &s3Body{ReadCloser: output.Body, cancel: cancel}, nil
+}
+
+type s3Body struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *s3Body) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close() //nolint:wrapcheck
+}
+
+// Check verifies the bucket exists and the credentials may access it.
+func (p *S3Provider) Check(ctx context.Context) error {
+	started := time.Now()
+	_, err := p.Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: &p.Bucket})
+	p.observer.observe("HeadBucket", started, err)
+
+	if err != nil {
+		return s3Error("failed to reach bucket "+p.Bucket, err)
+	}
+
+	return nil
+}
+
+// Checksums returns the SHA-256 S3 stored for the object. Objects uploaded in a single PutObject by Publish
+// have one; objects uploaded in parts only have a checksum of the part checksums, which is left out, as are
+// objects uploaded without a checksum.
+func (p *S3Provider) Checksums(ctx context.Context, artifact Artifact) (map[string]string, error) {
+	started := time.Now()
+	output, err := p.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       &p.Bucket,
+		Key:          aws.String(p.Prefix + artifact.Path),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	err = objectNotFound(err)
+	p.observer.observe("HeadObject", started, err)
+
+	if err != nil {
+		if errors.Is(err, ErrFileNotFound) {
+			return nil, fmt.Errorf("failed to head object %s: %w", artifact.Path, err)
+		}
+
+		return nil, s3Error("failed to head object "+artifact.Path, err)
+	}
+
+	if output.ChecksumSHA256 == nil || output.ChecksumType == types.ChecksumTypeComposite {
+		return nil, nil
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(*output.ChecksumSHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode checksum of %s: %w", artifact.Path, err)
+	}
+
+	return map[string]string{"sha256": hex.EncodeToString(sum)}, nil
+}
+
+// Delete removes the object at path. S3 reports success for keys that do not exist, so does Delete.
+func (p *S3Provider) Delete(ctx context.Context, _, _, path string) error {
+	if err := ValidatePath(path); err != nil {
+		return err
+	}
+
+	started := time.Now()
+	_, err := p.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &p.Bucket,
+		Key:    aws.String(p.Prefix + path),
+	})
+	p.observer.observe("DeleteObject", started, err)
+
+	if err != nil {
+		p.logger.WithError(err).Errorf("Failed to delete object %s", path)
+
+		return s3Error("failed to delete object "+path, err)
+	}
+
+	return nil
+}
+
+func (p *S3Provider) SetObserver(observer CallObserver) {
+	p.observer = observer
+}
+
+func (p *S3Provider) PresignGet(ctx context.Context, path string, expires time.Duration) (string, error) {
+	if p.Presigner == nil {
+		return "", ErrPresignUnsupported
+	}
+
+	if err := ValidatePath(path); err != nil {
+		return "", err
+	}
+
+	request, err := p.Presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: &p.Bucket,
+		Key:    aws.String(p.Prefix + path),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		p.logger.WithError(err).Errorf("Failed to presign object %s", path)
+
+		return "", s3Error("failed to presign object "+path, err)
+	}
+
+	return request.URL, nil
+}
+
+// Publish uses conditional writes (If-None-Match: *) so an existing object is never replaced.
+func (p *S3Provider) Publish(ctx context.Context, moduleName, artifactName, version, filename string,
+	body io.Reader) (Artifact, error) {
+	path, err := publishPath(layoutOrDefault(p.Layout), moduleName, artifactName, version, filename)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	contentType := ContentTypeForName(filename)
+
+	// The first part is read into a buffer sized by the upload, so small files stay small in memory.
+	first, err := io.ReadAll(io.LimitReader(body, s3PartSize))
+	if err != nil {
+		return Artifact{}, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	var (
+		size int64
+		etag string
+		hash = sha256.New()
+	)
+
+	hash.Write(first)
+
+	// The checksum is computed here either way; only a single PutObject lets S3 verify and keep it.
+	if len(first) < s3PartSize {
+		size = int64(len(first))
+		etag, err = p.putObject(ctx, p.Prefix+path, contentType, first, hash.Sum(nil))
+	} else {
+		size, etag, err = p.putMultipart(ctx, p.Prefix+path, contentType, first, io.TeeReader(body, hash))
+	}
+
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	return Artifact{
+		Path:         path,
+		Name:         filename,
+		Version:      version,
+		Size:         size,
+		LastModified: time.Now().UTC(),
+		ContentType:  contentType,
+		ETag:         strings.Trim(etag, `"`),
+		Checksums:    map[string]string{"sha256": hex.EncodeToString(hash.Sum(nil))},
+	}, nil
+}
+
+func (p *S3Provider) putObject(ctx context.Context, path, contentType string, data, sum []byte) (string, error) {
+	started := time.Now()
+	output, err := p.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:            &p.Bucket,
+		Key:               &path,
+		Body:              bytes.NewReader(data),
+		ContentLength:     aws.Int64(int64(len(data))),
+		ContentType:       &contentType,
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+		ChecksumSHA256:    aws.String(base64.StdEncoding.EncodeToString(sum)),
+		IfNoneMatch:       aws.String("*"),
+	})
+	p.observer.observe("PutObject", started, err)
+
+	if err != nil {
+		return "", p.publishError(path, err)
+	}
+
+	return aws.StringValue(output.ETag), nil
+}
+
+func (p *S3Provider) putMultipart(ctx context.Context, path, contentType string, first []byte,
+	body io.Reader) (int64, string, error) {
+	started := time.Now()
+	upload, err := p.Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      &p.Bucket,
+		Key:         &path,
+		ContentType: &contentType,
+	})
+	p.observer.observe("CreateMultipartUpload", started, err)
+
+	if err != nil {
+		return 0, "", p.publishError(path, err)
+	}
+
+	size, etag, err := p.uploadParts(ctx, path, upload.UploadId, first, body)
+	if err != nil {
+		if _, abortErr := p.Client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   &p.Bucket,
+			Key:      &path,
+			UploadId: upload.UploadId,
+		}); abortErr != nil {
+			p.logger.WithError(abortErr).Errorf("Failed to abort multipart upload of %s", path)
+		}
+
+		return 0, "", err
+	}
+
+	return size, etag, nil
+}
+
+// uploadParts uploads first as part one, then the rest of body in parts read into a pooled buffer.
+func (p *S3Provider) uploadParts(ctx context.Context, path string, uploadID *string, first []byte,
+	body io.Reader) (int64, string, error) {
+	var (
+		parts []types.CompletedPart
+		size  int64
+	)
+
+	pooled, _ := partBuffers.Get().(*[]byte)
+	defer partBuffers.Put(pooled)
+
+	buffer := first
+	count := len(buffer)
+
+	for partNumber := int32(1); count > 0; partNumber++ {
+		started := time.Now()
+		output, err := p.Client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        &p.Bucket,
+			Key:           &path,
+			UploadId:      uploadID,
+			PartNumber:    aws.Int32(partNumber),
+			Body:          bytes.NewReader(buffer[:count]),
+			ContentLength: aws.Int64(int64(count)),
+		})
+		p.observer.observe("UploadPart", started, err)
+
+		if err != nil {
+			return 0, "", p.publishError(path, err)
+		}
+
+		parts = append(parts, types.CompletedPart{ETag: output.ETag, PartNumber: aws.Int32(partNumber)})
+		size += int64(count)
+
+		buffer = *pooled
+		count, err = io.ReadFull(body, buffer)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, "", fmt.Errorf("failed to read upload: %w", err)
+		}
+	}
+
+	started := time.Now()
+	output, err := p.Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &p.Bucket,
+		Key:             &path,
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		IfNoneMatch:     aws.String("*"),
+	})
+	p.observer.observe("CompleteMultipartUpload", started, err)
+
+	if err != nil {
+		return 0, "", p.publishError(path, err)
+	}
+
+	return size, aws.StringValue(output.ETag), nil
+}
+
+func (p *S3Provider) publishError(path string, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && errPreconditionCodes[apiErr.ErrorCode()] {
+		return fmt.Errorf("%w: %s", ErrFileExists, path)
+	}
+
+	p.logger.WithError(err).Errorf("Failed to upload object %s", path)
+
+	return s3Error("failed to upload object "+path, err)
+}
+
+// objectNotFound marks the errors S3 returns for missing keys as ErrFileNotFound, before they are observed,
+// so missing files are not counted as failing calls; other errors are returned as they are.
+func objectNotFound(err error) error {
+	var (
+		noSuchKey *types.NoSuchKey
+		notFound  *types.NotFound
+	)
+
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) {
+		return fmt.Errorf("%w: %w", ErrFileNotFound, err)
+	}
+
+	return err
+}
+
+// s3Error marks a failed S3 call as ErrProviderUnavailable unless the request was cancelled.
+func s3Error(message string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s: %w", message, err)
 	}
 
-	return versions, nil
+	return fmt.Errorf("%s: %w: %w", message, ErrProviderUnavailable, err)
 }
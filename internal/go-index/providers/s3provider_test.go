@@ -1,11 +1,28 @@
 package providers_test
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/mauhlik/go-index/internal/go-index/mocks"
 	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/sirupsen/logrus"
 )
 
 func TestS3ProviderGetVersions(t *testing.T) {
@@ -24,7 +41,7 @@ func TestS3ProviderGetVersions(t *testing.T) {
 	artifactName := "app1"
 	expectedVersions := []string{"0.0.0", "0.0.1", "1.0.0", "2.0.0"}
 
-	gotVersions, err := provider.GetVersions(moduleName, artifactName)
+	gotVersions, err := provider.GetVersions(context.Background(), moduleName, artifactName)
 	if err != nil {
 		t.Fatalf("GetVersions returned an error: %v", err)
 	}
@@ -39,3 +56,406 @@ func TestS3ProviderGetVersions(t *testing.T) {
 		}
 	}
 }
+
+func TestS3ProviderGetFile(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	provider := &providers.S3Provider{
+		Client: mocks.NewMockS3Client(mockCtrl),
+		Bucket: "test-bucket",
+	}
+
+	body, err := provider.GetFile(context.Background(), "fe", "app1", "app1-1.0.0.txt")
+	if err != nil {
+		t.Fatalf("GetFile returned an error: %v", err)
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("Failed to read object: %v", err)
+	}
+
+	if string(content) != "fe/app1/app1-1.0.0.txt" {
+		t.Errorf("GetFile returned %q; want %q", content, "fe/app1/app1-1.0.0.txt")
+	}
+
+	if _, err := provider.GetFile(context.Background(), "be", "app2", "app2-1.0.0.txt"); !errors.Is(err, providers.ErrFileNotFound) {
+		t.Errorf("GetFile returned %v for a missing key; want %v", err, providers.ErrFileNotFound)
+	}
+}
+
+func TestS3ProviderGetVersionsWithLayout(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	layout, err := providers.NewTemplateLayout("{module}/{artifact}/{artifact}-{version}.txt")
+	if err != nil {
+		t.Fatalf("NewTemplateLayout returned an error: %v", err)
+	}
+
+	provider := &providers.S3Provider{
+		Client: mocks.NewMockS3Client(mockCtrl),
+		Bucket: "test-bucket",
+		Layout: layout,
+	}
+
+	gotVersions, err := provider.GetVersions(context.Background(), "fe", "app1")
+	if err != nil {
+		t.Fatalf("GetVersions returned an error: %v", err)
+	}
+
+	expectedVersions := []string{"0.0.0", "0.0.1", "1.0.0", "2.0.0"}
+	if len(gotVersions) != len(expectedVersions) {
+		t.Fatalf("GetVersions returned %v; want %v", gotVersions, expectedVersions)
+	}
+
+	for i, version := range gotVersions {
+		if version != expectedVersions[i] {
+			t.Errorf("GetVersions returned version %q; want %q", version, expectedVersions[i])
+		}
+	}
+}
+
+func TestS3ProviderListArtifacts(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	provider := &providers.S3Provider{
+		Client: mocks.NewMockS3Client(mockCtrl),
+		Bucket: "test-bucket",
+	}
+
+	artifacts, err := provider.ListArtifacts(context.Background(), "fe", "app1", "1.0.0")
+	if err != nil {
+		t.Fatalf("ListArtifacts returned an error: %v", err)
+	}
+
+	if len(artifacts) != 1 {
+		t.Fatalf("ListArtifacts returned %d artifacts; want 1", len(artifacts))
+	}
+
+	artifact := artifacts[0]
+	if artifact.Path != "fe/app1/app1-1.0.0.txt" || artifact.Size != 123 {
+		t.Errorf("ListArtifacts returned %+v; want fe/app1/app1-1.0.0.txt with size 123", artifact)
+	}
+
+	if artifact.ETag != "etag-1.0.0" || artifact.Checksums != nil {
+		t.Errorf("ListArtifacts returned etag %q and checksums %v; want %q and no checksums", artifact.ETag,
+			artifact.Checksums, "etag-1.0.0")
+	}
+}
+
+func TestS3ProviderPresignGet(t *testing.T) {
+	t.Parallel()
+
+	provider, err := providers.NewS3Provider(providers.S3Options{
+		Bucket:    "test-bucket",
+		Endpoint:  "http://localhost:9000",
+		Region:    "us-east-1",
+		AccessKey: "access",
+		SecretKey: "secret",
+	}, nil, logrus.New())
+	if err != nil {
+		t.Fatalf("NewS3Provider returned an error: %v", err)
+	}
+
+	url, err := provider.PresignGet(context.Background(), "fe/app1/app1-1.0.0.txt", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("PresignGet returned an error: %v", err)
+	}
+
+	if !strings.Contains(url, "test-bucket") || !strings.Contains(url, "fe/app1/app1-1.0.0.txt") ||
+		!strings.Contains(url, "X-Amz-Expires=300") {
+		t.Errorf("PresignGet returned %q; want a 5 minute URL for the object", url)
+	}
+
+	unsigned := &providers.S3Provider{Client: mocks.NewMockS3Client(gomock.NewController(t)), Bucket: "test-bucket"}
+	if _, err := unsigned.PresignGet(context.Background(), "fe/app1/app1-1.0.0.txt", time.Minute); !errors.Is(err, providers.ErrPresignUnsupported) {
+		t.Errorf("PresignGet returned %v; want %v", err, providers.ErrPresignUnsupported)
+	}
+}
+
+func TestS3ProviderPublish(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockS3Client := mocks.NewMockS3Client(mockCtrl)
+	provider := &providers.S3Provider{Client: mockS3Client, Bucket: "test-bucket"}
+
+	artifact, err := provider.Publish(context.Background(), "fe", "app1", "3.0.0", "app1-3.0.0.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatalf("Publish returned an error: %v", err)
+	}
+
+	if artifact.Path != "fe/app1/app1-3.0.0.txt" || artifact.Size != int64(len("content")) ||
+		string(mockS3Client.Uploads["fe/app1/app1-3.0.0.txt"]) != "content" {
+		t.Errorf("Publish returned %+v; want fe/app1/app1-3.0.0.txt to be uploaded", artifact)
+	}
+
+	expectedChecksum := "ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73"
+	if artifact.Checksums["sha256"] != expectedChecksum || artifact.ETag == "" {
+		t.Errorf("Publish returned etag %q and checksums %v; want an etag and sha256 %s", artifact.ETag,
+			artifact.Checksums, expectedChecksum)
+	}
+
+	checksums, err := provider.Checksums(context.Background(), artifact)
+	if err != nil || checksums["sha256"] != expectedChecksum {
+		t.Errorf("Checksums returned %v, %v; want the sha256 stored by S3", checksums, err)
+	}
+
+	_, err = provider.Publish(context.Background(), "fe", "app1", "1.0.0", "app1-1.0.0.txt", strings.NewReader("content"))
+	if !errors.Is(err, providers.ErrFileExists) {
+		t.Errorf("Publish returned %v; want %v", err, providers.ErrFileExists)
+	}
+}
+
+func TestS3ProviderDelete(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockS3Client := mocks.NewMockS3Client(mockCtrl)
+	provider := &providers.S3Provider{Client: mockS3Client, Bucket: "test-bucket", Prefix: "repo/"}
+
+	if err := provider.Delete(context.Background(), "fe", "app1", "fe/app1/app1-1.0.0.txt"); err != nil {
+		t.Fatalf("Delete returned an error: %v", err)
+	}
+
+	if len(mockS3Client.Deleted) != 1 || mockS3Client.Deleted[0] != "repo/fe/app1/app1-1.0.0.txt" {
+		t.Errorf("Delete removed %v; want repo/fe/app1/app1-1.0.0.txt", mockS3Client.Deleted)
+	}
+
+	if err := provider.Delete(context.Background(), "fe", "app1", "../app1.txt"); !errors.Is(err, providers.ErrInvalidPath) {
+		t.Errorf("Delete returned %v; want %v", err, providers.ErrInvalidPath)
+	}
+}
+
+func TestS3ProviderPublishMultipart(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockS3Client := mocks.NewMockS3Client(mockCtrl)
+	provider := &providers.S3Provider{Client: mockS3Client, Bucket: "test-bucket"}
+
+	content := strings.Repeat("x", 40<<20)
+
+	artifact, err := provider.Publish(context.Background(), "fe", "app1", "3.0.0", "app1-3.0.0.bin", strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Publish returned an error: %v", err)
+	}
+
+	if artifact.Size != int64(len(content)) || len(mockS3Client.Uploads["fe/app1/app1-3.0.0.bin"]) != len(content) {
+		t.Errorf("Publish uploaded %d bytes; want %d", len(mockS3Client.Uploads["fe/app1/app1-3.0.0.bin"]), len(content))
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	if artifact.Checksums["sha256"] != hex.EncodeToString(sum[:]) {
+		t.Errorf("Publish returned sha256 %q; want %x", artifact.Checksums["sha256"], sum)
+	}
+
+	// S3 only keeps a checksum of the part checksums, which must not be passed off as the file's.
+	if checksums, err := provider.Checksums(context.Background(), artifact); err != nil || checksums != nil {
+		t.Errorf("Checksums returned %v, %v; want none for a multipart upload", checksums, err)
+	}
+}
+
+func TestS3ProviderCheck(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	provider := &providers.S3Provider{Client: mocks.NewMockS3Client(mockCtrl), Bucket: "test-bucket"}
+	if err := provider.Check(context.Background()); err != nil {
+		t.Errorf("Check returned an error: %v", err)
+	}
+
+	provider.Bucket = "missing-bucket"
+	if err := provider.Check(context.Background()); err == nil {
+		t.Error("Check returned no error for a missing bucket")
+	}
+}
+
+// fakeS3 is a minimal path-style S3 endpoint serving ListObjectsV2 and GetObject from objects.
+type fakeS3 struct {
+	bucket  string
+	objects map[string]string
+}
+
+func (f fakeS3) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(request.URL.Path, "/"), "/")
+	if bucket != f.bucket || !strings.Contains(request.Header.Get("Authorization"), "Credential=access/") {
+		http.Error(writer, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+
+		return
+	}
+
+	if key != "" {
+		content, ok := f.objects[key]
+		if !ok {
+			http.Error(writer, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+
+			return
+		}
+
+		io.WriteString(writer, content)
+
+		return
+	}
+
+	query := request.URL.Query()
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+
+	// Entries are keys, or common prefixes when a delimiter follows the prefix; the continuation token
+	// is the index of the next entry.
+	var entries []string
+
+	for _, key := range slices.Sorted(maps.Keys(f.objects)) {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+
+		if index := strings.Index(rest, delimiter); delimiter != "" && index >= 0 {
+			key = prefix + rest[:index+len(delimiter)]
+		}
+
+		if len(entries) == 0 || entries[len(entries)-1] != key {
+			entries = append(entries, key)
+		}
+	}
+
+	start, _ := strconv.Atoi(query.Get("continuation-token"))
+	end := len(entries)
+
+	if maxKeys, err := strconv.Atoi(query.Get("max-keys")); err == nil && maxKeys > 0 {
+		end = min(end, start+maxKeys)
+	}
+
+	var contents strings.Builder
+
+	for _, entry := range entries[start:end] {
+		if strings.HasSuffix(entry, delimiter) && delimiter != "" {
+			fmt.Fprintf(&contents, "<CommonPrefixes><Prefix>%s</Prefix></CommonPrefixes>", entry)
+		} else {
+			fmt.Fprintf(&contents, "<Contents><Key>%s</Key><Size>%d</Size></Contents>", entry, len(f.objects[entry]))
+		}
+	}
+
+	if end < len(entries) {
+		fmt.Fprintf(&contents, "<NextContinuationToken>%d</NextContinuationToken>", end)
+	}
+
+	fmt.Fprintf(writer, `<ListBucketResult><Name>%s</Name><IsTruncated>%t</IsTruncated>%s</ListBucketResult>`,
+		f.bucket, end < len(entries), contents.String())
+}
+
+func TestS3ProviderAgainstEndpoint(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(fakeS3{bucket: "shared", objects: map[string]string{
+		"mirror/fe/app1/app1-1.0.0.txt": "one",
+		"mirror/fe/app1/app1-2.0.0.txt": "two",
+		"fe/app1/app1-3.0.0.txt":        "outside the prefix",
+	}})
+	defer server.Close()
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	if err := os.WriteFile(caBundle, certificate, 0600); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+
+	provider, err := providers.NewS3Provider(providers.S3Options{
+		Bucket:       "shared",
+		Endpoint:     server.URL,
+		Region:       "us-east-1",
+		AccessKey:    "access",
+		SecretKey:    "secret",
+		UsePathStyle: true,
+		CABundle:     caBundle,
+		Prefix:       "/mirror/",
+	}, nil, logrus.New())
+	if err != nil {
+		t.Fatalf("NewS3Provider returned an error: %v", err)
+	}
+
+	versions, err := provider.GetVersions(context.Background(), "fe", "app1")
+	if err != nil || strings.Join(versions, ",") != "1.0.0,2.0.0" {
+		t.Fatalf("GetVersions returned %v, %v; want [1.0.0 2.0.0] from the prefix", versions, err)
+	}
+
+	file, err := provider.GetFile(context.Background(), "fe", "app1", "app1-2.0.0.txt")
+	if err != nil {
+		t.Fatalf("GetFile returned an error: %v", err)
+	}
+	defer file.Close()
+
+	if content, _ := io.ReadAll(file); string(content) != "two" {
+		t.Errorf("GetFile returned %q; want %q", content, "two")
+	}
+
+	if _, err := provider.GetFile(context.Background(), "fe", "app1", "app1-3.0.0.txt"); !errors.Is(err, providers.ErrFileNotFound) {
+		t.Errorf("GetFile returned %v for a key outside the prefix; want %v", err, providers.ErrFileNotFound)
+	}
+}
+
+func TestS3ProviderListModules(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(fakeS3{bucket: "shared", objects: map[string]string{
+		"mirror/README.md":               "not a module",
+		"mirror/be/api/api-1.0.0.txt":    "api",
+		"mirror/fe/app1/app1-1.0.0.txt":  "one",
+		"mirror/fe/app1/app1-2.0.0.txt":  "two",
+		"mirror/fe/app2/app2-1.0.0.txt":  "other",
+		"mirror/ops/tool/tool-1.0.0.txt": "tool",
+		"outside/app3/app3-1.0.0.txt":    "outside the prefix",
+	}})
+	defer server.Close()
+
+	provider, err := providers.NewS3Provider(providers.S3Options{
+		Bucket:       "shared",
+		Endpoint:     server.URL,
+		Region:       "us-east-1",
+		AccessKey:    "access",
+		SecretKey:    "secret",
+		UsePathStyle: true,
+		Prefix:       "mirror",
+	}, nil, logrus.New())
+	if err != nil {
+		t.Fatalf("NewS3Provider returned an error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// README.md takes one of the keys of the first request, so a second one fills the page.
+	first, err := provider.ListModules(ctx, providers.Page{Limit: 2, Cursor: ""})
+	if err != nil || strings.Join(first.Names, ",") != "be,fe" || first.Next == "" {
+		t.Fatalf("ListModules returned %+v, %v; want [be fe] and a next page", first, err)
+	}
+
+	second, err := provider.ListModules(ctx, providers.Page{Limit: 2, Cursor: first.Next})
+	if err != nil || strings.Join(second.Names, ",") != "ops" || second.Next != "" {
+		t.Errorf("ListModules returned %+v, %v; want the last page [ops]", second, err)
+	}
+
+	artifacts, err := provider.ListArtifactNames(ctx, "fe", providers.Page{Limit: 10, Cursor: ""})
+	if err != nil || strings.Join(artifacts.Names, ",") != "app1,app2" || artifacts.Next != "" {
+		t.Errorf("ListArtifactNames returned %+v, %v; want [app1 app2]", artifacts, err)
+	}
+}
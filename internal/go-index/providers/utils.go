@@ -1,10 +1,18 @@
 package providers
 
 import (
+	"errors"
+	"fmt"
+	"mime"
+	"path"
 	"path/filepath"
 	"strings"
 )
 
+const defaultContentType = "application/octet-stream"
+
+var ErrInvalidPath = errors.New("invalid path")
+
 func ExtractVersionFromFilename(filename, artifactName string) string {
 	if strings.HasPrefix(filename, artifactName+"-") {
 		version := strings.TrimPrefix(filename, artifactName+"-")
@@ -37,3 +45,48 @@ func ContainsNumbers(s string) bool {
 
 	return false
 }
+
+func ValidatePath(path string) error {
+	if path == "" || strings.HasPrefix(path, "/") || strings.Contains(path, "\\") {
+		return fmt.Errorf("%w: %q", ErrInvalidPath, path)
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidPath, path)
+		}
+	}
+
+	return nil
+}
+
+func SafeJoin(basePath string, elems ...string) (string, error) {
+	parts := []string{basePath}
+
+	for _, elem := range elems {
+		if err := ValidatePath(elem); err != nil {
+			return "", err
+		}
+
+		parts = append(parts, filepath.FromSlash(elem))
+	}
+
+	return filepath.Join(parts...), nil
+}
+
+func ContentTypeForName(name string) string {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType
+	}
+
+	return defaultContentType
+}
+
+// pageOf returns the first limit of the sorted names, continuing after the last one returned.
+func pageOf(names []string, limit int) Listing {
+	if limit <= 0 || len(names) <= limit {
+		return Listing{Names: names, Next: ""}
+	}
+
+	return Listing{Names: names[:limit], Next: names[limit-1]}
+}
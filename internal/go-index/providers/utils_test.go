@@ -67,3 +67,34 @@ func TestContainsNumbers(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		isValid bool
+	}{
+		{"fe", true},
+		{"github.com/!azure/sdk", true},
+		{"app1-1.0.0.txt", true},
+		{"", false},
+		{"/etc", false},
+		{"fe/../be", false},
+		{"fe//app", false},
+		{"..", false},
+		{"fe\\app", false},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.input, func(t *testing.T) {
+			t.Parallel()
+
+			err := providers.ValidatePath(testCase.input)
+
+			if (err == nil) != testCase.isValid {
+				t.Errorf("ValidatePath(%q) = %v; want valid %v", testCase.input, err, testCase.isValid)
+			}
+		})
+	}
+}
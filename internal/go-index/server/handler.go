@@ -0,0 +1,130 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mauhlik/go-index/config"
+	"github.com/mauhlik/go-index/internal/go-index/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// Handler serves the repositories of the current configuration. Reload swaps in a new configuration
+// atomically: requests already running finish on the routes they started with.
+type Handler struct {
+	metrics *metrics.Metrics
+	logger  *logrus.Logger
+
+	reloading sync.Mutex
+	current   atomic.Pointer[routes]
+	retired   sync.WaitGroup
+}
+
+func NewHandler(cfg *config.Config, appMetrics *metrics.Metrics, logger *logrus.Logger) (*Handler, error) {
+	built, err := buildRoutes(cfg, appMetrics, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := &Handler{
+		metrics:   appMetrics,
+		logger:    logger,
+		reloading: sync.Mutex{},
+		current:   atomic.Pointer[routes]{},
+		retired:   sync.WaitGroup{},
+	}
+	handler.current.Store(built)
+	handler.registerCaches(nil, built)
+
+	return handler, nil
+}
+
+func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	for {
+		current := h.current.Load()
+		current.mutex.RLock()
+
+		// The routes were replaced and closed between loading and locking them; the new ones are stored.
+		if current.closed {
+			current.mutex.RUnlock()
+
+			continue
+		}
+
+		defer current.mutex.RUnlock()
+
+		current.engine.ServeHTTP(writer, request)
+
+		return
+	}
+}
+
+// Reload builds the routes for cfg and swaps them in. When cfg is invalid the error is returned and the
+// current configuration keeps serving.
+func (h *Handler) Reload(cfg *config.Config) error {
+	h.reloading.Lock()
+	defer h.reloading.Unlock()
+
+	built, err := buildRoutes(cfg, h.metrics, h.logger)
+	if err != nil {
+		return err
+	}
+
+	built.search.Inherit(h.current.Load().search)
+
+	old := h.current.Swap(built)
+	h.registerCaches(old, built)
+
+	for _, change := range config.Diff(old.config, cfg) {
+		if change.RestartRequired() {
+			h.logger.Warnf("Configuration %s; restart to apply it", change)
+		} else {
+			h.logger.Infof("Configuration %s", change)
+		}
+	}
+
+	h.retired.Add(1)
+
+	go func() {
+		defer h.retired.Done()
+
+		old.mutex.Lock()
+		old.closed = true
+		old.mutex.Unlock()
+
+		old.close()
+	}()
+
+	return nil
+}
+
+// Close waits for replaced configurations to drain and closes the caches of the current one.
+func (h *Handler) Close() error {
+	h.reloading.Lock()
+	defer h.reloading.Unlock()
+
+	h.retired.Wait()
+
+	current := h.current.Load()
+	current.mutex.Lock()
+	defer current.mutex.Unlock()
+
+	current.close()
+
+	return nil
+}
+
+func (h *Handler) registerCaches(old, updated *routes) {
+	if old != nil {
+		for name := range old.caches {
+			if _, ok := updated.caches[name]; !ok {
+				h.metrics.UnregisterCache(name)
+			}
+		}
+	}
+
+	for name, cache := range updated.caches {
+		h.metrics.RegisterCache(name, cache.Stats)
+	}
+}
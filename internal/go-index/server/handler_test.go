@@ -0,0 +1,218 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/config"
+	"github.com/mauhlik/go-index/internal/go-index/controllers"
+	"github.com/mauhlik/go-index/internal/go-index/metrics"
+	"github.com/mauhlik/go-index/internal/go-index/server"
+	"github.com/sirupsen/logrus"
+)
+
+func testConfig(dir string, repositories ...string) *config.Config {
+	cfg := &config.Config{
+		Providers: map[string]interface{}{"local": config.LocalProviderConfig{Type: "local", Path: dir}},
+	}
+
+	for _, name := range repositories {
+		cfg.Repositories = append(cfg.Repositories, config.RepositoryConfig{
+			Name:     name,
+			Provider: "local",
+			Cache:    config.CacheConfig{TTL: config.Duration(time.Minute)},
+		})
+	}
+
+	return cfg
+}
+
+func getStatus(t *testing.T, handler http.Handler, path string) int {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return recorder.Code
+}
+
+func TestHandlerReload(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "fe", "app1", "app1-1.0.0.txt")
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	if err := os.WriteFile(filename, []byte("content"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	handler, err := server.NewHandler(testConfig(dir, "files"), metrics.New(), logrus.New())
+	if err != nil {
+		t.Fatalf("NewHandler returned an error: %v", err)
+	}
+	defer handler.Close()
+
+	if status := getStatus(t, handler, "/api/charts/fe/app1/versions"); status != http.StatusNotFound {
+		t.Fatalf("Unconfigured repository returned %d; want %d", status, http.StatusNotFound)
+	}
+
+	if err := handler.Reload(testConfig(dir, "files", "charts")); err != nil {
+		t.Fatalf("Reload returned an error: %v", err)
+	}
+
+	if status := getStatus(t, handler, "/api/charts/fe/app1/versions"); status != http.StatusOK {
+		t.Errorf("Added repository returned %d; want %d", status, http.StatusOK)
+	}
+
+	invalid := testConfig(dir, "files")
+	invalid.Repositories[0].Provider = "missing"
+
+	if err := handler.Reload(invalid); err == nil {
+		t.Error("Reload accepted a repository with an unknown provider")
+	}
+
+	for _, path := range []string{"/api/files/fe/app1/versions", "/api/charts/fe/app1/versions"} {
+		if status := getStatus(t, handler, path); status != http.StatusOK {
+			t.Errorf("%s returned %d after a failed reload; want %d", path, status, http.StatusOK)
+		}
+	}
+}
+
+func getBody(t *testing.T, handler http.Handler, path string, target interface{}) int {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), target); err != nil {
+			t.Fatalf("%s returned invalid JSON: %v", path, err)
+		}
+	}
+
+	return recorder.Code
+}
+
+func TestHandlerDiscovery(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+
+	for _, path := range []string{"fe/app1/app1-1.0.0.txt", "fe/app2/app2-1.0.0.txt", "be/api/api-1.0.0.txt"} {
+		filename := filepath.Join(dir, filepath.FromSlash(path))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte("content"), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	cfg := testConfig(dir, "files", "charts", "private")
+	cfg.Repositories[2].Access.Anonymous = "none"
+
+	handler, err := server.NewHandler(cfg, metrics.New(), logrus.New())
+	if err != nil {
+		t.Fatalf("NewHandler returned an error: %v", err)
+	}
+	defer handler.Close()
+
+	var repositories controllers.RepositoryList
+	if status := getBody(t, handler, "/api?limit=1", &repositories); status != http.StatusOK ||
+		len(repositories.Repositories) != 1 || repositories.Repositories[0].Name != "charts" ||
+		repositories.Repositories[0].Mode != config.RepositoryModeGeneric || repositories.Next != "charts" {
+		t.Errorf("GET /api?limit=1 returned %d %+v; want the charts repository and a next page", status, repositories)
+	}
+
+	repositories = controllers.RepositoryList{}
+	if status := getBody(t, handler, "/api?cursor=charts", &repositories); status != http.StatusOK ||
+		len(repositories.Repositories) != 1 || repositories.Repositories[0].Name != "files" || repositories.Next != "" {
+		t.Errorf("GET /api?cursor=charts returned %d %+v; want only files, private is hidden", status, repositories)
+	}
+
+	var modules controllers.ModuleList
+	if status := getBody(t, handler, "/api/files", &modules); status != http.StatusOK ||
+		!slices.Equal(modules.Modules, []string{"be", "fe"}) {
+		t.Errorf("GET /api/files returned %d %+v; want [be fe]", status, modules)
+	}
+
+	var artifacts controllers.ArtifactList
+	if status := getBody(t, handler, "/api/files/fe?limit=1", &artifacts); status != http.StatusOK ||
+		!slices.Equal(artifacts.Artifacts, []string{"app1"}) || artifacts.Next != "app1" {
+		t.Errorf("GET /api/files/fe?limit=1 returned %d %+v; want [app1] and a next page", status, artifacts)
+	}
+
+	for path, want := range map[string]int{
+		"/api/files/missing":          http.StatusNotFound,
+		"/api/files?limit=0":          http.StatusBadRequest,
+		"/api/private":                http.StatusUnauthorized,
+		"/api/files/fe/app1/versions": http.StatusOK,
+	} {
+		if status := getStatus(t, handler, path); status != want {
+			t.Errorf("GET %s returned %d; want %d", path, status, want)
+		}
+	}
+}
+
+func TestHandlerSearch(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "fe", "app1", "app1-1.0.0.txt")
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	if err := os.WriteFile(filename, []byte("content"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	cfg := testConfig(dir, "files", "private")
+	cfg.Repositories[1].Access.Anonymous = "none"
+
+	handler, err := server.NewHandler(cfg, metrics.New(), logrus.New())
+	if err != nil {
+		t.Fatalf("NewHandler returned an error: %v", err)
+	}
+	defer handler.Close()
+
+	var response controllers.SearchResponse
+
+	// The index is built in the background; until then the endpoint answers 503.
+	deadline := time.Now().Add(5 * time.Second)
+	for getBody(t, handler, "/api/search?q=app", &response) != http.StatusOK {
+		if time.Now().After(deadline) {
+			t.Fatal("GET /api/search never became available")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(response.Results) != 1 || response.Results[0].Repository != "files" || response.Results[0].Latest != "1.0.0" {
+		t.Errorf("GET /api/search?q=app returned %+v; want app1 1.0.0 of files only", response.Results)
+	}
+
+	if status := getStatus(t, handler, "/api/search"); status != http.StatusBadRequest {
+		t.Errorf("GET /api/search without a query returned %d; want %d", status, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,384 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/config"
+	"github.com/mauhlik/go-index/internal/go-index/apierror"
+	"github.com/mauhlik/go-index/internal/go-index/auth"
+	"github.com/mauhlik/go-index/internal/go-index/controllers"
+	"github.com/mauhlik/go-index/internal/go-index/metrics"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrProviderNotFound       = errors.New("provider not found")
+	ErrUnknownProviderType    = errors.New("unknown provider type")
+	ErrUnknownMode            = errors.New("unknown repository mode")
+	ErrJWKSSource             = errors.New("exactly one of jwksFile and jwksUrl is required")
+	ErrRepositoryNotFound     = errors.New("repository not found")
+	ErrVersionsUnsupported    = errors.New("repository mode does not serve versions")
+	ErrReservedRepositoryName = errors.New("repository name is reserved")
+)
+
+// routes is everything built from one configuration; it is replaced as a whole on reload.
+type routes struct {
+	config *config.Config
+	engine *gin.Engine
+	caches map[string]*providers.CachingProvider
+	search *services.SearchIndex
+
+	// mutex is held for reading by every request, so a replaced configuration can wait for its requests
+	// to finish before its caches are closed.
+	mutex  sync.RWMutex
+	closed bool
+}
+
+func (r *routes) close() {
+	if r.search != nil {
+		r.search.Close()
+	}
+
+	for _, cache := range r.caches {
+		cache.Close()
+	}
+}
+
+func buildRoutes(cfg *config.Config, appMetrics *metrics.Metrics, logger *logrus.Logger) (*routes, error) {
+	authenticator, err := setupAuthenticator(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup authentication: %w", err)
+	}
+
+	built := &routes{
+		config: cfg,
+		engine: gin.Default(),
+		caches: map[string]*providers.CachingProvider{},
+		search: nil,
+		mutex:  sync.RWMutex{},
+		closed: false,
+	}
+	built.engine.Use(apierror.RequestID())
+	built.engine.NoRoute(controllers.NotFound)
+	built.engine.GET("/metrics", appMetrics.Handler)
+
+	var checks []controllers.HealthCheck
+
+	checked := map[string]bool{}
+	summaries := make([]controllers.RepositorySummary, 0, len(cfg.Repositories))
+	policies := make(map[string]auth.Policy, len(cfg.Repositories))
+	sources := make([]services.SearchSource, 0, len(cfg.Repositories))
+
+	for _, repo := range cfg.Repositories {
+		policy, err := auth.NewPolicy(repo.Access.Anonymous, repo.Access.Roles)
+		if err != nil {
+			built.close()
+
+			return nil, fmt.Errorf("failed to setup access for repository %s: %w", repo.Name, err)
+		}
+
+		provider, err := setupProviderForRepository(cfg, repo, logger)
+		if err != nil {
+			built.close()
+
+			return nil, err
+		}
+
+		if checker, ok := provider.(providers.Checker); ok && !checked[repo.Provider] {
+			checked[repo.Provider] = true
+			checks = append(checks, controllers.HealthCheck{Name: repo.Provider, Check: checker.Check})
+		}
+
+		if observable, ok := provider.(providers.Observable); ok {
+			observable.SetObserver(appMetrics.ProviderObserver(repo.Name))
+		}
+
+		if repo.Cache.TTL > 0 {
+			cache := providers.NewCachingProvider(
+				provider, repo.Name, repo.Cache.TTL.Duration(), repo.Cache.StaleTTL.Duration(),
+				repo.Cache.MaxEntries, logger,
+			)
+			built.caches[repo.Name] = cache
+			provider = cache
+		}
+
+		source, err := registerRepository(built.engine, repo, provider, authenticator, policy, appMetrics, logger)
+		if err != nil {
+			built.close()
+
+			return nil, fmt.Errorf("failed to setup repository %s: %w", repo.Name, err)
+		}
+
+		if source != nil {
+			sources = append(sources, *source)
+		}
+
+		mode := repo.Mode
+		if mode == "" {
+			mode = config.RepositoryModeGeneric
+		}
+
+		summaries = append(summaries, controllers.RepositorySummary{Name: repo.Name, Mode: mode, Publish: repo.Publish})
+		policies[repo.Name] = policy
+	}
+
+	readable := func(ctx *gin.Context, repository string) bool {
+		return policies[repository].RoleFor(repository, auth.IdentityFrom(ctx)) >= auth.RoleRead
+	}
+
+	repositoryController := controllers.NewRepositoryController(summaries, readable)
+	built.engine.GET("/api", auth.Identify(authenticator, logger), repositoryController.ListRepositories)
+
+	built.search = services.NewSearchIndex(sources, cfg.Search.RefreshInterval.Duration(), logger)
+	searchController := controllers.NewSearchController(built.search, readable, logger)
+	built.engine.GET("/api/search", auth.Identify(authenticator, logger), searchController.Search)
+
+	healthController := controllers.NewHealthController(checks, cfg.Health.Timeout.Duration(), logger)
+	built.engine.GET("/healthz", healthController.Healthz)
+	built.engine.GET("/readyz", healthController.Readyz)
+
+	built.search.Start()
+
+	return built, nil
+}
+
+// registerRepository returns the repository's search source, nil for repositories that cannot be searched.
+func registerRepository(router *gin.Engine, repo config.RepositoryConfig, provider providers.Provider,
+	authenticator auth.Authenticator, policy auth.Policy, appMetrics *metrics.Metrics,
+	logger *logrus.Logger) (*services.SearchSource, error) {
+	if repo.Name == config.ReservedRepositoryName {
+		return nil, fmt.Errorf("%w: %s", ErrReservedRepositoryName, repo.Name)
+	}
+
+	group := router.Group("/api/" + repo.Name)
+	group.Use(appMetrics.Middleware(repo.Name))
+	group.Use(auth.Middleware(repo.Name, authenticator, policy, auth.RoleRead, logger))
+
+	// Uploads are not bounded by the timeout since they stream the request body to the provider.
+	reads := group.Group("", controllers.RequestTimeout(repo.Timeout.Duration()))
+
+	switch repo.Mode {
+	case "", config.RepositoryModeGeneric:
+		scheme, invalidVersions, err := parseVersioning(repo)
+		if err != nil {
+			return nil, err
+		}
+
+		discoveryService := services.NewDiscoveryService(provider, logger)
+		discoveryController := controllers.NewDiscoveryController(discoveryService, logger)
+		reads.GET("", discoveryController.ListModules)
+		reads.GET("/:module", discoveryController.ListArtifacts)
+
+		versionService := services.NewService(provider, scheme, invalidVersions, logger)
+		versionService.SetSkipObserver(appMetrics.SkipObserver(repo.Name))
+		versionController := controllers.NewVersionController(versionService, logger)
+		reads.GET("/:module/:artifact/versions", versionController.GetVersions)
+		reads.GET("/:module/:artifact/versions/latest", versionController.GetLatestVersion)
+		reads.GET("/:module/:artifact/versions/resolve", versionController.ResolveVersion)
+		reads.GET("/:module/:artifact/versions/:version", versionController.GetVersionDetails)
+
+		downloadService := services.NewDownloadService(
+			provider, repo.Download.Redirect, repo.Download.PresignExpiry.Duration(), logger,
+		)
+		downloadController := controllers.NewDownloadController(downloadService, logger)
+		reads.GET("/:module/:artifact/versions/:version/download", downloadController.Download)
+		reads.GET("/:module/:artifact/versions/:version/download/:file", downloadController.Download)
+
+		if repo.Publish {
+			publishService := services.NewPublishService(provider, scheme, logger)
+			publishController := controllers.NewPublishController(publishService, logger)
+			requirePublish := auth.Middleware(repo.Name, authenticator, policy, auth.RolePublish, logger)
+			group.PUT("/:module/:artifact/versions/:version", requirePublish, publishController.Publish)
+			group.POST("/:module/:artifact/versions/:version", requirePublish, publishController.Publish)
+
+			requireAdmin := auth.Middleware(repo.Name, authenticator, policy, auth.RoleAdmin, logger)
+			group.DELETE("/:module/:artifact/versions/:version", requireAdmin, publishController.DeleteVersion)
+		}
+
+		return &services.SearchSource{Repository: repo.Name, Discovery: discoveryService, Versions: versionService}, nil
+	case config.RepositoryModeGoProxy:
+		goProxyService := services.NewGoProxyService(provider, logger)
+		goProxyController := controllers.NewGoProxyController(goProxyService, logger)
+		reads.GET("/*path", goProxyController.Handle)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMode, repo.Mode)
+	}
+
+	return nil, nil
+}
+
+// NewVersionService builds the version service of the named repository without the HTTP layer or
+// caches, so the repository can be queried directly.
+//
+//nolint:ireturn
+func NewVersionService(cfg *config.Config, name string, logger *logrus.Logger) (services.VersionService, error) {
+	index := slices.IndexFunc(cfg.Repositories, func(repo config.RepositoryConfig) bool { return repo.Name == name })
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRepositoryNotFound, name)
+	}
+
+	repo := cfg.Repositories[index]
+	if repo.Mode != "" && repo.Mode != config.RepositoryModeGeneric {
+		return nil, fmt.Errorf("%w: %s is a %s repository", ErrVersionsUnsupported, name, repo.Mode)
+	}
+
+	scheme, invalidVersions, err := parseVersioning(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := setupProviderForRepository(cfg, repo, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return services.NewService(provider, scheme, invalidVersions, logger), nil
+}
+
+func parseVersioning(repo config.RepositoryConfig) (versioning.Scheme, services.InvalidVersionPolicy, error) {
+	invalidVersions, err := services.ParseInvalidVersionPolicy(repo.InvalidVersions)
+	if err != nil {
+		return nil, "", err //nolint:wrapcheck
+	}
+
+	scheme, err := versioning.Lookup(repo.Scheme)
+	if err != nil {
+		return nil, "", err //nolint:wrapcheck
+	}
+
+	return scheme, invalidVersions, nil
+}
+
+//nolint:ireturn
+func setupAuthenticator(cfg *config.Config, logger *logrus.Logger) (auth.Authenticator, error) {
+	users := make([]auth.User, 0, len(cfg.Auth.Users))
+
+	for _, user := range cfg.Auth.Users {
+		tokenHashes := make([]string, 0, len(user.TokenHashes))
+		for _, tokenHash := range user.TokenHashes {
+			tokenHashes = append(tokenHashes, tokenHash.Value())
+		}
+
+		users = append(users, auth.User{
+			Name:         user.Name,
+			PasswordHash: user.PasswordHash.Value(),
+			TokenHashes:  tokenHashes,
+		})
+	}
+
+	authenticator, err := auth.NewStaticAuthenticator(users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	chain := auth.Chain{authenticator}
+
+	if cfg.Auth.OIDC.Issuer != "" {
+		oidcAuthenticator, err := setupOIDCAuthenticator(cfg.Auth.OIDC, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup OIDC: %w", err)
+		}
+
+		chain = append(auth.Chain{oidcAuthenticator}, chain...)
+	}
+
+	// Explicit credentials win over the client certificate, which every request carries under mutual TLS.
+	if cfg.Server.TLS.ClientCAFile != "" {
+		chain = append(chain, auth.ClientCertAuthenticator{})
+	}
+
+	return chain, nil
+}
+
+func setupOIDCAuthenticator(conf config.OIDCConfig, logger *logrus.Logger) (*auth.OIDCAuthenticator, error) {
+	if (conf.JWKSFile == "") == (conf.JWKSURL == "") {
+		return nil, ErrJWKSSource
+	}
+
+	source := conf.JWKSFile
+	if source == "" {
+		source = conf.JWKSURL
+	}
+
+	keys, err := auth.NewJWKS(source, conf.JWKSRefresh.Duration(), logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWKS: %w", err)
+	}
+
+	rules := make([]auth.OIDCRule, 0, len(conf.Rules))
+
+	for _, rule := range conf.Rules {
+		repositories := make(map[string]auth.Role, len(rule.Repositories))
+
+		for repository, roleName := range rule.Repositories {
+			role, err := auth.ParseRole(roleName)
+			if err != nil {
+				return nil, fmt.Errorf("invalid role for repository %s: %w", repository, err)
+			}
+
+			repositories[repository] = role
+		}
+
+		rules = append(rules, auth.OIDCRule{Claims: rule.Claims, Repositories: repositories})
+	}
+
+	authenticator, err := auth.NewOIDCAuthenticator(auth.OIDCConfig{
+		Issuer:        conf.Issuer,
+		Audience:      conf.Audience,
+		UsernameClaim: conf.UsernameClaim,
+		Rules:         rules,
+	}, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OIDC authenticator: %w", err)
+	}
+
+	return authenticator, nil
+}
+
+//nolint:ireturn
+func setupProviderForRepository(cfg *config.Config, repo config.RepositoryConfig,
+	logger *logrus.Logger) (providers.Provider, error) {
+	providerConfig, ok := cfg.Providers[repo.Provider]
+	if !ok {
+		return nil, fmt.Errorf("%w for repository %s: %s", ErrProviderNotFound, repo.Name, repo.Provider)
+	}
+
+	layout, err := providers.NewLayout(repo.Layout, repo.LayoutRegex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse layout for repository %s: %w", repo.Name, err)
+	}
+
+	var provider providers.Provider
+
+	switch conf := providerConfig.(type) {
+	case config.LocalProviderConfig:
+		provider = providers.NewLocalProvider(conf.Path, layout)
+	case config.S3ProviderConfig:
+		provider, err = providers.NewS3Provider(providers.S3Options{
+			Bucket:        conf.Bucket,
+			Endpoint:      conf.Endpoint,
+			Region:        conf.Region,
+			AccessKey:     conf.AccessKey.Value(),
+			SecretKey:     conf.SecretKey.Value(),
+			Profile:       conf.Profile,
+			AssumeRoleARN: conf.AssumeRoleARN,
+			UsePathStyle:  conf.UsePathStyle,
+			CABundle:      conf.CABundle,
+			Prefix:        conf.Prefix,
+		}, layout, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize S3 provider for repository %s: %w", repo.Name, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w for repository %s", ErrUnknownProviderType, repo.Name)
+	}
+
+	return provider, nil
+}
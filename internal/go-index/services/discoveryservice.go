@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrListingUnsupported = providers.ErrListingUnsupported
+
+type DiscoveryService interface {
+	ListModules(ctx context.Context, page providers.Page) (providers.Listing, error)
+	ListArtifacts(ctx context.Context, moduleName string, page providers.Page) (providers.Listing, error)
+}
+
+type DiscoveryServiceImpl struct {
+	provider providers.Provider
+	logger   *logrus.Logger
+}
+
+func NewDiscoveryService(provider providers.Provider, logger *logrus.Logger) *DiscoveryServiceImpl {
+	return &DiscoveryServiceImpl{provider: provider, logger: logger}
+}
+
+func (ds *DiscoveryServiceImpl) ListModules(ctx context.Context, page providers.Page) (providers.Listing, error) {
+	lister, ok := ds.provider.(providers.Lister)
+	if !ok {
+		return providers.Listing{}, ErrListingUnsupported
+	}
+
+	listing, err := lister.ListModules(ctx, page)
+	if err != nil {
+		ds.logger.WithError(err).Error("Failed to list modules")
+
+		return providers.Listing{}, fmt.Errorf("failed to list modules from provider: %w", err)
+	}
+
+	return listing, nil
+}
+
+// ListArtifacts reports ErrModuleNotFound for a module without artifacts, since storage such as S3 has no
+// empty directories to tell the two apart.
+func (ds *DiscoveryServiceImpl) ListArtifacts(ctx context.Context, moduleName string,
+	page providers.Page) (providers.Listing, error) {
+	lister, ok := ds.provider.(providers.Lister)
+	if !ok {
+		return providers.Listing{}, ErrListingUnsupported
+	}
+
+	listing, err := lister.ListArtifactNames(ctx, moduleName, page)
+	if err != nil {
+		ds.logger.WithError(err).Errorf("Failed to list artifacts of %s", moduleName)
+
+		return providers.Listing{}, fmt.Errorf("failed to list artifacts from provider: %w", err)
+	}
+
+	if len(listing.Names) == 0 && page.Cursor == "" {
+		return providers.Listing{}, fmt.Errorf("%w: %s", ErrModuleNotFound, moduleName)
+	}
+
+	return listing, nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultPresignExpiry = 5 * time.Minute
+
+var (
+	ErrAmbiguousDownload = errors.New("version has multiple files, a file name is required")
+	ErrFileNotFound      = providers.ErrFileNotFound
+)
+
+// Download is either a presigned RedirectURL or a Body the caller must close.
+type Download struct {
+	Artifact    providers.Artifact
+	RedirectURL string
+	Body        io.ReadCloser
+}
+
+type DownloadService interface {
+	Open(ctx context.Context, moduleName, artifactName, version, filename string) (*Download, error)
+}
+
+type DownloadServiceImpl struct {
+	provider      providers.Provider
+	redirect      bool
+	presignExpiry time.Duration
+	logger        *logrus.Logger
+}
+
+func NewDownloadService(provider providers.Provider, redirect bool, presignExpiry time.Duration,
+	logger *logrus.Logger) *DownloadServiceImpl {
+	if presignExpiry <= 0 {
+		presignExpiry = DefaultPresignExpiry
+	}
+
+	return &DownloadServiceImpl{provider: provider, redirect: redirect, presignExpiry: presignExpiry, logger: logger}
+}
+
+func (ds *DownloadServiceImpl) Open(ctx context.Context, moduleName, artifactName, version,
+	filename string) (*Download, error) {
+	ds.logger.Infof("Opening download for module: %s, artifact: %s, version: %s, file: %s",
+		moduleName, artifactName, version, filename)
+
+	artifacts, err := ds.provider.ListArtifacts(ctx, moduleName, artifactName, version)
+	if err != nil {
+		ds.logger.WithError(err).Errorf("Failed to list artifacts for %s/%s@%s", moduleName, artifactName, version)
+
+		return nil, fmt.Errorf("failed to list artifacts from provider: %w", err)
+	}
+
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s@%s", ErrVersionNotFound, moduleName, artifactName, version)
+	}
+
+	artifact, err := selectArtifact(artifacts, filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s/%s@%s", err, moduleName, artifactName, version)
+	}
+
+	if ds.redirect {
+		if presigner, ok := ds.provider.(providers.Presigner); ok {
+			url, err := presigner.PresignGet(ctx, artifact.Path, ds.presignExpiry)
+
+			switch {
+			case err == nil:
+				return &Download{Artifact: artifact, RedirectURL: url, Body: nil}, nil
+			case !errors.Is(err, providers.ErrPresignUnsupported):
+				ds.logger.WithError(err).Errorf("Failed to presign %s", artifact.Path)
+
+				return nil, fmt.Errorf("failed to presign download: %w", err)
+			}
+		}
+	}
+
+	body, err := ds.provider.Open(ctx, artifact.Path)
+	if err != nil {
+		if !errors.Is(err, providers.ErrFileNotFound) {
+			ds.logger.WithError(err).Errorf("Failed to open %s", artifact.Path)
+		}
+
+		return nil, fmt.Errorf("failed to open file from provider: %w", err)
+	}
+
+	return &Download{Artifact: artifact, RedirectURL: "", Body: body}, nil
+}
+
+func selectArtifact(artifacts []providers.Artifact, filename string) (providers.Artifact, error) {
+	if filename == "" {
+		if len(artifacts) == 1 {
+			return artifacts[0], nil
+		}
+
+		names := make([]string, 0, len(artifacts))
+		for _, artifact := range artifacts {
+			names = append(names, artifact.Name)
+		}
+
+		return providers.Artifact{}, fmt.Errorf("%w (%s)", ErrAmbiguousDownload, strings.Join(names, ", "))
+	}
+
+	for _, artifact := range artifacts {
+		if artifact.Name == filename {
+			return artifact, nil
+		}
+	}
+
+	return providers.Artifact{}, fmt.Errorf("%w: %s", ErrFileNotFound, filename)
+}
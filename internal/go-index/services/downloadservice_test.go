@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mauhlik/go-index/internal/go-index/mocks"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/sirupsen/logrus"
+)
+
+type presigningProvider struct {
+	*mocks.MockProvider
+}
+
+func (presigningProvider) PresignGet(_ context.Context, path string, expires time.Duration) (string, error) {
+	return "https://example.com/" + path + "?expires=" + expires.String(), nil
+}
+
+func TestDownloadServiceOpen(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockProvider := mocks.NewMockProviderWithVersions(mockCtrl, []string{"1.0.0"})
+	service := services.NewDownloadService(mockProvider, false, 0, logrus.New())
+
+	download, err := service.Open(context.Background(), "fe", "app1", "1.0.0", "")
+	if err != nil {
+		t.Fatalf("Open returned an error: %v", err)
+	}
+	defer download.Body.Close()
+
+	content, err := io.ReadAll(download.Body)
+	if err != nil {
+		t.Fatalf("Failed to read download: %v", err)
+	}
+
+	if string(content) != "fe/app1/app1-1.0.0.txt" || download.RedirectURL != "" {
+		t.Errorf("Open returned content %q and redirect %q; want the streamed file", content, download.RedirectURL)
+	}
+
+	if _, err := service.Open(context.Background(), "fe", "app1", "1.0.0", "other.txt"); !errors.Is(err, services.ErrFileNotFound) {
+		t.Errorf("Open returned %v; want %v", err, services.ErrFileNotFound)
+	}
+
+	if _, err := service.Open(context.Background(), "fe", "app1", "9.9.9", ""); !errors.Is(err, services.ErrVersionNotFound) {
+		t.Errorf("Open returned %v; want %v", err, services.ErrVersionNotFound)
+	}
+}
+
+func TestDownloadServiceOpenAmbiguous(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockProvider := mocks.NewMockProvider(mockCtrl)
+	service := services.NewDownloadService(mockProvider, false, 0, logrus.New())
+
+	if _, err := service.Open(context.Background(), "fe", "app1", "", ""); !errors.Is(err, services.ErrAmbiguousDownload) {
+		t.Errorf("Open returned %v; want %v", err, services.ErrAmbiguousDownload)
+	}
+}
+
+func TestDownloadServiceOpenRedirect(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	provider := presigningProvider{mocks.NewMockProvider(mockCtrl)}
+
+	download, err := services.NewDownloadService(provider, true, 0, logrus.New()).
+		Open(context.Background(), "fe", "app1", "1.0.0", "app1-1.0.0.txt")
+	if err != nil {
+		t.Fatalf("Open returned an error: %v", err)
+	}
+
+	if download.Body != nil || download.RedirectURL != "https://example.com/fe/app1/app1-1.0.0.txt?expires=5m0s" {
+		t.Errorf("Open returned redirect %q; want a presigned URL with the default expiry", download.RedirectURL)
+	}
+
+	download, err = services.NewDownloadService(provider, false, 0, logrus.New()).
+		Open(context.Background(), "fe", "app1", "1.0.0", "app1-1.0.0.txt")
+	if err != nil {
+		t.Fatalf("Open returned an error: %v", err)
+	}
+	defer download.Body.Close()
+
+	if download.RedirectURL != "" {
+		t.Errorf("Open returned redirect %q with redirects disabled", download.RedirectURL)
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+	"github.com/sirupsen/logrus"
+)
+
+const goProxyVersionDir = "@v"
+
+var (
+	ErrModuleNotFound  = errors.New("module not found")
+	ErrVersionNotFound = errors.New("version not found")
+	ErrInvalidModule   = errors.New("invalid module path")
+	ErrInvalidVersion  = versioning.ErrInvalidVersion
+)
+
+type ModuleInfo struct {
+	Version string     `json:"Version"`
+	Time    *time.Time `json:"Time,omitempty"`
+}
+
+type GoProxyService interface {
+	List(ctx context.Context, modulePath string) ([]string, error)
+	Latest(ctx context.Context, modulePath string) (*ModuleInfo, error)
+	Info(ctx context.Context, modulePath, version string) (*ModuleInfo, error)
+	Mod(ctx context.Context, modulePath, version string) (io.ReadCloser, error)
+	Zip(ctx context.Context, modulePath, version string) (io.ReadCloser, error)
+}
+
+type GoProxyServiceImpl struct {
+	provider providers.Provider
+	logger   *logrus.Logger
+}
+
+func NewGoProxyService(provider providers.Provider, logger *logrus.Logger) *GoProxyServiceImpl {
+	return &GoProxyServiceImpl{provider: provider, logger: logger}
+}
+
+func (gs *GoProxyServiceImpl) List(ctx context.Context, modulePath string) ([]string, error) {
+	gs.logger.Infof("Listing versions for Go module: %s", modulePath)
+
+	if err := providers.ValidatePath(modulePath); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidModule, err)
+	}
+
+	files, err := gs.provider.ListFiles(ctx, modulePath, goProxyVersionDir)
+	if err != nil {
+		if errors.Is(err, providers.ErrFileNotFound) {
+			return []string{}, nil
+		}
+
+		gs.logger.WithError(err).Errorf("Failed to list versions for %s", modulePath)
+
+		return nil, fmt.Errorf("failed to list files from provider: %w", err)
+	}
+
+	versions := []string{}
+
+	for _, filename := range files {
+		version, ok := strings.CutSuffix(filename, ".mod")
+		if !ok {
+			continue
+		}
+
+		if _, err := semver.ParseTolerant(version); err != nil {
+			gs.logger.WithError(err).Warnf("Skipping invalid Go module version: %s", version)
+
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+func (gs *GoProxyServiceImpl) Latest(ctx context.Context, modulePath string) (*ModuleInfo, error) {
+	versions, err := gs.List(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		latest      string
+		latestSem   semver.Version
+		latestFound bool
+	)
+
+	for _, version := range versions {
+		semVersion, err := semver.ParseTolerant(version)
+		if err != nil {
+			continue
+		}
+
+		if !latestFound || isPreferredGoVersion(semVersion, latestSem) {
+			latest, latestSem, latestFound = version, semVersion, true
+		}
+	}
+
+	if !latestFound {
+		return nil, fmt.Errorf("%w: %s", ErrModuleNotFound, modulePath)
+	}
+
+	return gs.Info(ctx, modulePath, latest)
+}
+
+func (gs *GoProxyServiceImpl) Info(ctx context.Context, modulePath, version string) (*ModuleInfo, error) {
+	body, err := gs.open(ctx, modulePath, version, ".info")
+	if err != nil {
+		if !errors.Is(err, ErrVersionNotFound) {
+			return nil, err
+		}
+
+		mod, modErr := gs.open(ctx, modulePath, version, ".mod")
+		if modErr != nil {
+			return nil, modErr
+		}
+
+		mod.Close()
+
+		return &ModuleInfo{Version: version, Time: nil}, nil
+	}
+	defer body.Close()
+
+	var info ModuleInfo
+
+	if err := json.NewDecoder(body).Decode(&info); err != nil {
+		gs.logger.WithError(err).Errorf("Failed to decode info file for %s@%s", modulePath, version)
+
+		return nil, fmt.Errorf("failed to decode info file: %w", err)
+	}
+
+	if info.Version == "" {
+		info.Version = version
+	}
+
+	return &info, nil
+}
+
+func (gs *GoProxyServiceImpl) Mod(ctx context.Context, modulePath, version string) (io.ReadCloser, error) {
+	return gs.open(ctx, modulePath, version, ".mod")
+}
+
+func (gs *GoProxyServiceImpl) Zip(ctx context.Context, modulePath, version string) (io.ReadCloser, error) {
+	return gs.open(ctx, modulePath, version, ".zip")
+}
+
+func (gs *GoProxyServiceImpl) open(ctx context.Context, modulePath, version, ext string) (io.ReadCloser, error) {
+	gs.logger.Infof("Fetching %s file for Go module: %s@%s", ext, modulePath, version)
+
+	if err := providers.ValidatePath(modulePath); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidModule, err)
+	}
+
+	if strings.Contains(version, "/") {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidVersion, version)
+	}
+
+	if _, err := semver.ParseTolerant(version); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidVersion, version, err)
+	}
+
+	body, err := gs.provider.GetFile(ctx, modulePath, goProxyVersionDir, version+ext)
+	if err != nil {
+		if errors.Is(err, providers.ErrFileNotFound) {
+			return nil, fmt.Errorf("%w: %s@%s", ErrVersionNotFound, modulePath, version)
+		}
+
+		gs.logger.WithError(err).Errorf("Failed to get %s file for %s@%s", ext, modulePath, version)
+
+		return nil, fmt.Errorf("failed to get file from provider: %w", err)
+	}
+
+	return body, nil
+}
+
+func isPreferredGoVersion(candidate, current semver.Version) bool {
+	candidateRelease := len(candidate.Pre) == 0
+	currentRelease := len(current.Pre) == 0
+
+	if candidateRelease != currentRelease {
+		return candidateRelease
+	}
+
+	return candidate.GT(current)
+}
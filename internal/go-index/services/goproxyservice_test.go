@@ -0,0 +1,162 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/sirupsen/logrus"
+)
+
+func newGoProxyService(t *testing.T, files map[string]string) *services.GoProxyServiceImpl {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	for name, content := range files {
+		filename := filepath.Join(tempDir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	return services.NewGoProxyService(providers.NewLocalProvider(tempDir, providers.DefaultLayout{}), logrus.New())
+}
+
+func TestGoProxyServiceList(t *testing.T) {
+	t.Parallel()
+
+	service := newGoProxyService(t, map[string]string{
+		"example.com/lib/@v/v1.0.0.mod":        "module example.com/lib\n",
+		"example.com/lib/@v/v1.0.0.zip":        "zip",
+		"example.com/lib/@v/v1.1.0-beta.1.mod": "module example.com/lib\n",
+		"example.com/lib/@v/list":              "",
+		"example.com/lib/@v/latest.mod":        "module example.com/lib\n",
+	})
+
+	gotVersions, err := service.List(context.Background(), "example.com/lib")
+	if err != nil {
+		t.Fatalf("List returned an error: %v", err)
+	}
+
+	expectedVersions := []string{"v1.0.0", "v1.1.0-beta.1"}
+	if len(gotVersions) != len(expectedVersions) {
+		t.Fatalf("List returned %v; want %v", gotVersions, expectedVersions)
+	}
+
+	for i, version := range gotVersions {
+		if version != expectedVersions[i] {
+			t.Errorf("List returned version %q; want %q", version, expectedVersions[i])
+		}
+	}
+
+	gotVersions, err = service.List(context.Background(), "example.com/missing")
+	if err != nil {
+		t.Fatalf("List returned an error for a missing module: %v", err)
+	}
+
+	if len(gotVersions) != 0 {
+		t.Errorf("List returned %v for a missing module; want none", gotVersions)
+	}
+}
+
+func TestGoProxyServiceLatest(t *testing.T) {
+	t.Parallel()
+
+	service := newGoProxyService(t, map[string]string{
+		"example.com/lib/@v/v1.0.0.mod":        "module example.com/lib\n",
+		"example.com/lib/@v/v1.0.0.info":       `{"Version":"v1.0.0","Time":"2024-01-02T03:04:05Z"}`,
+		"example.com/lib/@v/v1.2.0.mod":        "module example.com/lib\n",
+		"example.com/lib/@v/v2.0.0-beta.1.mod": "module example.com/lib\n",
+		"example.com/pre/@v/v0.1.0-rc.1.mod":   "module example.com/pre\n",
+		"example.com/pre/@v/v0.1.0-rc.2.mod":   "module example.com/pre\n",
+	})
+
+	tests := []struct {
+		modulePath string
+		expected   string
+	}{
+		{"example.com/lib", "v1.2.0"},
+		{"example.com/pre", "v0.1.0-rc.2"},
+	}
+
+	for _, testCase := range tests {
+		info, err := service.Latest(context.Background(), testCase.modulePath)
+		if err != nil {
+			t.Fatalf("Latest(%q) returned an error: %v", testCase.modulePath, err)
+		}
+
+		if info.Version != testCase.expected {
+			t.Errorf("Latest(%q) = %q; want %q", testCase.modulePath, info.Version, testCase.expected)
+		}
+	}
+
+	if _, err := service.Latest(context.Background(), "example.com/missing"); !errors.Is(err, services.ErrModuleNotFound) {
+		t.Errorf("Latest returned %v for a missing module; want %v", err, services.ErrModuleNotFound)
+	}
+}
+
+func TestGoProxyServiceInfoAndFiles(t *testing.T) {
+	t.Parallel()
+
+	service := newGoProxyService(t, map[string]string{
+		"example.com/lib/@v/v1.0.0.mod":  "module example.com/lib\n",
+		"example.com/lib/@v/v1.0.0.info": `{"Version":"v1.0.0","Time":"2024-01-02T03:04:05Z"}`,
+		"example.com/lib/@v/v1.0.0.zip":  "zip-content",
+		"example.com/lib/@v/v1.1.0.mod":  "module example.com/lib\n",
+	})
+
+	info, err := service.Info(context.Background(), "example.com/lib", "v1.0.0")
+	if err != nil {
+		t.Fatalf("Info returned an error: %v", err)
+	}
+
+	if info.Time == nil || info.Time.Year() != 2024 {
+		t.Errorf("Info returned time %v; want 2024-01-02T03:04:05Z", info.Time)
+	}
+
+	info, err = service.Info(context.Background(), "example.com/lib", "v1.1.0")
+	if err != nil {
+		t.Fatalf("Info returned an error for a version without info file: %v", err)
+	}
+
+	if info.Version != "v1.1.0" || info.Time != nil {
+		t.Errorf("Info returned %+v; want synthesized info for v1.1.0", info)
+	}
+
+	body, err := service.Zip(context.Background(), "example.com/lib", "v1.0.0")
+	if err != nil {
+		t.Fatalf("Zip returned an error: %v", err)
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("Failed to read zip: %v", err)
+	}
+
+	if string(content) != "zip-content" {
+		t.Errorf("Zip returned %q; want %q", content, "zip-content")
+	}
+
+	if _, err := service.Zip(context.Background(), "example.com/lib", "v1.1.0"); !errors.Is(err, services.ErrVersionNotFound) {
+		t.Errorf("Zip returned %v for a missing file; want %v", err, services.ErrVersionNotFound)
+	}
+
+	if _, err := service.Mod(context.Background(), "example.com/../lib", "v1.0.0"); !errors.Is(err, services.ErrInvalidModule) {
+		t.Errorf("Mod returned %v for an invalid module path; want %v", err, services.ErrInvalidModule)
+	}
+
+	if _, err := service.Mod(context.Background(), "example.com/lib", "latest"); !errors.Is(err, services.ErrInvalidVersion) {
+		t.Errorf("Mod returned %v for an invalid version; want %v", err, services.ErrInvalidVersion)
+	}
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+type InvalidVersionPolicy string
+
+const (
+	InvalidVersionSkip   InvalidVersionPolicy = "skip"
+	InvalidVersionStrict InvalidVersionPolicy = "strict"
+	InvalidVersionCoerce InvalidVersionPolicy = "coerce"
+)
+
+var ErrUnknownInvalidVersionPolicy = errors.New("unknown invalid version policy")
+
+func ParseInvalidVersionPolicy(value string) (InvalidVersionPolicy, error) {
+	switch policy := InvalidVersionPolicy(value); policy {
+	case "":
+		return InvalidVersionSkip, nil
+	case InvalidVersionSkip, InvalidVersionStrict, InvalidVersionCoerce:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownInvalidVersionPolicy, value)
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrPublishUnsupported = providers.ErrPublishUnsupported
+	ErrDeleteUnsupported  = providers.ErrDeleteUnsupported
+	ErrVersionExists      = errors.New("version already exists")
+)
+
+// Upload is one file of a publish; Body is read once.
+type Upload struct {
+	Filename string
+	Body     io.Reader
+}
+
+// Uploads returns the next file of a publish, and io.EOF after the last one.
+type Uploads func() (Upload, error)
+
+type PublishService interface {
+	Publish(ctx context.Context, moduleName, artifactName, version string, uploads Uploads) ([]providers.Artifact, error)
+	DeleteVersion(ctx context.Context, moduleName, artifactName, version string) error
+}
+
+type PublishServiceImpl struct {
+	provider providers.Provider
+	scheme   versioning.Scheme
+	logger   *logrus.Logger
+}
+
+func NewPublishService(provider providers.Provider, scheme versioning.Scheme,
+	logger *logrus.Logger) *PublishServiceImpl {
+	return &PublishServiceImpl{provider: provider, scheme: scheme, logger: logger}
+}
+
+// Publish stores every upload as one release of version. Released versions are immutable, so a version
+// that already has files is rejected with ErrVersionExists before anything is written. Only versions
+// valid in the repository's scheme as written are accepted; coercion would store files under a name the
+// policy may later skip. When an upload fails, the files already written are deleted if the provider can.
+func (ps *PublishServiceImpl) Publish(ctx context.Context, moduleName, artifactName, version string,
+	uploads Uploads) ([]providers.Artifact, error) {
+	publisher, ok := ps.provider.(providers.Publisher)
+	if !ok {
+		return nil, ErrPublishUnsupported
+	}
+
+	if _, err := ps.scheme.Parse(version); err != nil {
+		return nil, err
+	}
+
+	if err := ps.checkUnreleased(ctx, moduleName, artifactName, version); err != nil {
+		return nil, err
+	}
+
+	files := []providers.Artifact{}
+
+	for {
+		upload, err := uploads()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+
+		if err != nil {
+			ps.rollback(ctx, moduleName, artifactName, files)
+
+			return nil, err
+		}
+
+		ps.logger.Infof("Publishing %s for module: %s, artifact: %s, version: %s", upload.Filename, moduleName,
+			artifactName, version)
+
+		artifact, err := publisher.Publish(ctx, moduleName, artifactName, version, upload.Filename, upload.Body)
+		if err != nil {
+			if !errors.Is(err, providers.ErrFileExists) && !errors.Is(err, providers.ErrLayoutMismatch) &&
+				!errors.Is(err, providers.ErrInvalidPath) {
+				ps.logger.WithError(err).Errorf("Failed to publish %s to %s/%s@%s", upload.Filename, moduleName,
+					artifactName, version)
+			}
+
+			ps.rollback(ctx, moduleName, artifactName, files)
+
+			return nil, fmt.Errorf("failed to publish %s: %w", upload.Filename, err)
+		}
+
+		files = append(files, artifact)
+	}
+}
+
+// rollback deletes the files a failed publish already wrote, so retrying it is not rejected with
+// ErrVersionExists. It runs even when ctx was cancelled, since that is a common reason to fail.
+func (ps *PublishServiceImpl) rollback(ctx context.Context, moduleName, artifactName string,
+	files []providers.Artifact) {
+	deleter, ok := ps.provider.(providers.Deleter)
+	if !ok {
+		if len(files) > 0 {
+			ps.logger.Warnf("Cannot remove %d files of a failed publish to %s/%s", len(files), moduleName, artifactName)
+		}
+
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	for _, file := range files {
+		if err := deleter.Delete(ctx, moduleName, artifactName, file.Path); err != nil {
+			ps.logger.WithError(err).Errorf("Failed to remove %s of a failed publish", file.Path)
+		}
+	}
+}
+
+// DeleteVersion removes every file of version, which may then be published again. Files deleted before a
+// failure stay deleted; deleting the version again removes the rest.
+func (ps *PublishServiceImpl) DeleteVersion(ctx context.Context, moduleName, artifactName, version string) error {
+	deleter, ok := ps.provider.(providers.Deleter)
+	if !ok {
+		return ErrDeleteUnsupported
+	}
+
+	artifacts, err := ps.provider.ListArtifacts(ctx, moduleName, artifactName, version)
+	if err != nil && !errors.Is(err, ErrArtifactNotFound) {
+		return fmt.Errorf("failed to list artifacts from provider: %w", err)
+	}
+
+	if len(artifacts) == 0 {
+		return fmt.Errorf("%w: %s/%s@%s", ErrVersionNotFound, moduleName, artifactName, version)
+	}
+
+	for _, artifact := range artifacts {
+		ps.logger.Infof("Deleting %s of module: %s, artifact: %s, version: %s", artifact.Path, moduleName,
+			artifactName, version)
+
+		if err := deleter.Delete(ctx, moduleName, artifactName, artifact.Path); err != nil {
+			ps.logger.WithError(err).Errorf("Failed to delete %s of %s/%s@%s", artifact.Path, moduleName,
+				artifactName, version)
+
+			return fmt.Errorf("failed to delete %s: %w", artifact.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// checkUnreleased fails when version already has files. Two publishes of a new version racing each other
+// can both pass; the providers still never replace a file.
+func (ps *PublishServiceImpl) checkUnreleased(ctx context.Context, moduleName, artifactName, version string) error {
+	artifacts, err := ps.provider.ListArtifacts(ctx, moduleName, artifactName, version)
+
+	switch {
+	case errors.Is(err, ErrArtifactNotFound):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to check version %s: %w", version, err)
+	case len(artifacts) > 0:
+		return fmt.Errorf("%w: %s/%s@%s", ErrVersionExists, moduleName, artifactName, version)
+	default:
+		return nil
+	}
+}
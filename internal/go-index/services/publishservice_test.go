@@ -0,0 +1,134 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mauhlik/go-index/internal/go-index/mocks"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+	"github.com/sirupsen/logrus"
+)
+
+// uploadsOf returns one upload per filename, each holding "content".
+func uploadsOf(filenames ...string) services.Uploads {
+	return func() (services.Upload, error) {
+		if len(filenames) == 0 {
+			return services.Upload{}, io.EOF
+		}
+
+		upload := services.Upload{Filename: filenames[0], Body: strings.NewReader("content")}
+		filenames = filenames[1:]
+
+		return upload, nil
+	}
+}
+
+func TestPublishServicePublish(t *testing.T) {
+	t.Parallel()
+
+	provider := providers.NewLocalProvider(t.TempDir(), providers.DefaultLayout{})
+	service := services.NewPublishService(provider, versioning.SemverScheme{}, logrus.New())
+	ctx := context.Background()
+
+	files, err := service.Publish(ctx, "fe", "app1", "1.0.0", uploadsOf("app1-1.0.0.txt", "app1-1.0.0.zip"))
+	if err != nil {
+		t.Fatalf("Publish returned an error: %v", err)
+	}
+
+	if len(files) != 2 || files[0].Version != "1.0.0" || files[0].Name != "app1-1.0.0.txt" ||
+		files[1].Name != "app1-1.0.0.zip" {
+		t.Errorf("Publish returned %+v; want app1-1.0.0.txt and app1-1.0.0.zip of version 1.0.0", files)
+	}
+
+	_, err = service.Publish(ctx, "fe", "app1", "v1", uploadsOf("app1-v1.txt"))
+	if !errors.Is(err, versioning.ErrInvalidVersion) {
+		t.Errorf("Publish returned %v; want %v", err, versioning.ErrInvalidVersion)
+	}
+
+	for _, filename := range []string{"app1-1.0.0.txt", "app1-1.0.0.tgz"} {
+		_, err = service.Publish(ctx, "fe", "app1", "1.0.0", uploadsOf(filename))
+		if !errors.Is(err, services.ErrVersionExists) {
+			t.Errorf("Publish of %s to a released version returned %v; want %v", filename, err,
+				services.ErrVersionExists)
+		}
+	}
+
+	if _, err := provider.Open(ctx, "fe/app1/app1-1.0.0.tgz"); !errors.Is(err, providers.ErrFileNotFound) {
+		t.Errorf("Open of a rejected file returned %v; want %v", err, providers.ErrFileNotFound)
+	}
+}
+
+func TestPublishServiceRollsBackFailedPublish(t *testing.T) {
+	t.Parallel()
+
+	provider := providers.NewLocalProvider(t.TempDir(), providers.DefaultLayout{})
+	service := services.NewPublishService(provider, versioning.SemverScheme{}, logrus.New())
+	ctx := context.Background()
+
+	_, err := service.Publish(ctx, "fe", "app1", "1.0.0", uploadsOf("app1-1.0.0.txt", "../app1-1.0.0.zip"))
+	if !errors.Is(err, providers.ErrInvalidPath) {
+		t.Fatalf("Publish returned %v; want %v", err, providers.ErrInvalidPath)
+	}
+
+	if _, err := provider.Open(ctx, "fe/app1/app1-1.0.0.txt"); !errors.Is(err, providers.ErrFileNotFound) {
+		t.Errorf("Open of the first file returned %v; want %v after the failed publish", err, providers.ErrFileNotFound)
+	}
+
+	files, err := service.Publish(ctx, "fe", "app1", "1.0.0", uploadsOf("app1-1.0.0.txt", "app1-1.0.0.zip"))
+	if err != nil || len(files) != 2 {
+		t.Errorf("Publish retry returned %v, %v; want both files", files, err)
+	}
+}
+
+func TestPublishServiceUnsupportedProvider(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	service := services.NewPublishService(mocks.NewMockProvider(mockCtrl), versioning.SemverScheme{}, logrus.New())
+
+	_, err := service.Publish(context.Background(), "fe", "app1", "1.0.0", uploadsOf("app1-1.0.0.txt"))
+	if !errors.Is(err, services.ErrPublishUnsupported) {
+		t.Errorf("Publish returned %v; want %v", err, services.ErrPublishUnsupported)
+	}
+
+	err = service.DeleteVersion(context.Background(), "fe", "app1", "1.0.0")
+	if !errors.Is(err, services.ErrDeleteUnsupported) {
+		t.Errorf("DeleteVersion returned %v; want %v", err, services.ErrDeleteUnsupported)
+	}
+}
+
+func TestPublishServiceDeleteVersion(t *testing.T) {
+	t.Parallel()
+
+	provider := providers.NewLocalProvider(t.TempDir(), providers.DefaultLayout{})
+	service := services.NewPublishService(provider, versioning.SemverScheme{}, logrus.New())
+	ctx := context.Background()
+
+	if _, err := service.Publish(ctx, "fe", "app1", "1.0.0", uploadsOf("app1-1.0.0.txt", "app1-1.0.0.zip")); err != nil {
+		t.Fatalf("Publish returned an error: %v", err)
+	}
+
+	if err := service.DeleteVersion(ctx, "fe", "app1", "1.0.0"); err != nil {
+		t.Fatalf("DeleteVersion returned an error: %v", err)
+	}
+
+	if _, err := provider.Open(ctx, "fe/app1/app1-1.0.0.zip"); !errors.Is(err, providers.ErrFileNotFound) {
+		t.Errorf("Open of a deleted file returned %v; want %v", err, providers.ErrFileNotFound)
+	}
+
+	if err := service.DeleteVersion(ctx, "fe", "app1", "1.0.0"); !errors.Is(err, services.ErrVersionNotFound) {
+		t.Errorf("DeleteVersion of a deleted version returned %v; want %v", err, services.ErrVersionNotFound)
+	}
+
+	if _, err := service.Publish(ctx, "fe", "app1", "1.0.0", uploadsOf("app1-1.0.0.txt")); err != nil {
+		t.Errorf("Publish of a deleted version returned an error: %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultSearchRefreshInterval = 10 * time.Minute
+
+	// searchPageLimit is the page size used to walk the listings of a repository.
+	searchPageLimit = 1000
+)
+
+type SearchMatch string
+
+// Matches in order of relevance.
+const (
+	SearchMatchExact     SearchMatch = "exact"
+	SearchMatchPrefix    SearchMatch = "prefix"
+	SearchMatchSubstring SearchMatch = "substring"
+)
+
+var searchMatches = []SearchMatch{SearchMatchExact, SearchMatchPrefix, SearchMatchSubstring}
+
+type SearchResult struct {
+	Repository string      `json:"repository"`
+	Module     string      `json:"module"`
+	Artifact   string      `json:"artifact"`
+	Latest     string      `json:"latest,omitempty"`
+	Match      SearchMatch `json:"match"`
+}
+
+// SearchSource is a repository covered by the search index.
+type SearchSource struct {
+	Repository string
+	Discovery  DiscoveryService
+	Versions   VersionService
+}
+
+type SearchService interface {
+	// Search returns at most limit results from the repositories visible reports, and when the index was
+	// last built; a zero time means it has not been built yet.
+	Search(query string, limit int, visible func(repository string) bool) ([]SearchResult, time.Time)
+}
+
+// SearchIndex keeps the module and artifact names of its repositories with their latest versions in
+// memory, rebuilding them every interval so queries never scan storage. A repository whose scan fails
+// keeps its previous entries.
+type SearchIndex struct {
+	sources  []SearchSource
+	interval time.Duration
+	logger   *logrus.Logger
+
+	mutex     sync.RWMutex
+	entries   map[string][]SearchResult
+	indexedAt time.Time
+
+	closing context.Context
+	close   context.CancelFunc
+	started atomic.Bool
+	done    chan struct{}
+}
+
+func NewSearchIndex(sources []SearchSource, interval time.Duration, logger *logrus.Logger) *SearchIndex {
+	if interval <= 0 {
+		interval = DefaultSearchRefreshInterval
+	}
+
+	closing, closeFunc := context.WithCancel(context.Background())
+
+	return &SearchIndex{
+		sources:   sources,
+		interval:  interval,
+		logger:    logger,
+		mutex:     sync.RWMutex{},
+		entries:   map[string][]SearchResult{},
+		indexedAt: time.Time{},
+		closing:   closing,
+		close:     closeFunc,
+		started:   atomic.Bool{},
+		done:      make(chan struct{}),
+	}
+}
+
+// Start builds the index in the background and rebuilds it every interval until Close.
+func (i *SearchIndex) Start() {
+	if !i.started.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer close(i.done)
+
+		ticker := time.NewTicker(i.interval)
+		defer ticker.Stop()
+
+		for {
+			i.Refresh(i.closing)
+
+			select {
+			case <-i.closing.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops refreshing and waits for a running scan to end.
+func (i *SearchIndex) Close() {
+	i.close()
+
+	if i.started.Load() {
+		<-i.done
+	}
+}
+
+// Inherit copies the entries of the repositories this index shares with previous, so an index replaced on
+// reload keeps answering while the first scan of its successor runs. Entries already scanned are kept.
+func (i *SearchIndex) Inherit(previous *SearchIndex) {
+	previous.mutex.RLock()
+	entries := maps.Clone(previous.entries)
+	indexedAt := previous.indexedAt
+	previous.mutex.RUnlock()
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, source := range i.sources {
+		if _, scanned := i.entries[source.Repository]; !scanned && entries[source.Repository] != nil {
+			i.entries[source.Repository] = entries[source.Repository]
+		}
+	}
+
+	if i.indexedAt.IsZero() {
+		i.indexedAt = indexedAt
+	}
+}
+
+// Refresh scans every repository once.
+func (i *SearchIndex) Refresh(ctx context.Context) {
+	started := time.Now()
+
+	for _, source := range i.sources {
+		entries, err := i.scan(ctx, source)
+
+		switch {
+		case errors.Is(err, ErrListingUnsupported):
+			i.logger.Debugf("Repository %s cannot be listed and is not searchable", source.Repository)
+
+			continue
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			i.logger.WithError(err).Warnf("Failed to index repository %s, keeping its previous entries",
+				source.Repository)
+
+			continue
+		}
+
+		i.mutex.Lock()
+		i.entries[source.Repository] = entries
+		i.mutex.Unlock()
+	}
+
+	i.mutex.Lock()
+	i.indexedAt = time.Now()
+	i.mutex.Unlock()
+
+	i.logger.Infof("Indexed %d repositories for search in %s", len(i.sources), time.Since(started))
+}
+
+func (i *SearchIndex) scan(ctx context.Context, source SearchSource) ([]SearchResult, error) {
+	var entries []SearchResult
+
+	page := providers.Page{Limit: searchPageLimit, Cursor: ""}
+
+	for {
+		modules, err := source.Discovery.ListModules(ctx, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list modules: %w", err)
+		}
+
+		for _, module := range modules.Names {
+			artifacts, err := i.scanModule(ctx, source, module)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, artifacts...)
+		}
+
+		if modules.Next == "" {
+			return entries, nil
+		}
+
+		page.Cursor = modules.Next
+	}
+}
+
+func (i *SearchIndex) scanModule(ctx context.Context, source SearchSource, module string) ([]SearchResult, error) {
+	var entries []SearchResult
+
+	page := providers.Page{Limit: searchPageLimit, Cursor: ""}
+
+	for {
+		artifacts, err := source.Discovery.ListArtifacts(ctx, module, page)
+
+		switch {
+		case errors.Is(err, ErrModuleNotFound):
+			return nil, nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to list artifacts of %s: %w", module, err)
+		}
+
+		for _, artifact := range artifacts.Names {
+			entry := SearchResult{Repository: source.Repository, Module: module, Artifact: artifact, Latest: "", Match: ""}
+
+			// An artifact without valid versions is still found, just without a latest version.
+			if resolution, err := source.Versions.GetLatestVersion(ctx, module, artifact); err == nil {
+				entry.Latest = resolution.Version
+			} else if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to resolve latest version of %s/%s: %w", module, artifact, err)
+			}
+
+			entries = append(entries, entry)
+		}
+
+		if artifacts.Next == "" {
+			return entries, nil
+		}
+
+		page.Cursor = artifacts.Next
+	}
+}
+
+// Search matches the query case-insensitively against module names, artifact names and module/artifact
+// paths. Results are ordered by relevance, then by repository, module and artifact.
+func (i *SearchIndex) Search(query string, limit int, visible func(repository string) bool) ([]SearchResult,
+	time.Time) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	results := []SearchResult{}
+
+	i.mutex.RLock()
+	indexedAt := i.indexedAt
+
+	for repository, entries := range i.entries {
+		if !visible(repository) {
+			continue
+		}
+
+		for _, entry := range entries {
+			if match, ok := matchEntry(query, entry); ok {
+				entry.Match = match
+				results = append(results, entry)
+			}
+		}
+	}
+	i.mutex.RUnlock()
+
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(
+			cmp.Compare(matchRank(a.Match), matchRank(b.Match)),
+			strings.Compare(a.Repository, b.Repository),
+			strings.Compare(a.Module, b.Module),
+			strings.Compare(a.Artifact, b.Artifact),
+		)
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, indexedAt
+}
+
+func matchEntry(query string, entry SearchResult) (SearchMatch, bool) {
+	names := []string{
+		strings.ToLower(entry.Artifact),
+		strings.ToLower(entry.Module),
+		strings.ToLower(entry.Module + "/" + entry.Artifact),
+	}
+
+	for _, match := range searchMatches {
+		for _, name := range names {
+			if (match == SearchMatchExact && name == query) ||
+				(match == SearchMatchPrefix && strings.HasPrefix(name, query)) ||
+				(match == SearchMatchSubstring && strings.Contains(name, query)) {
+				return match, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func matchRank(match SearchMatch) int {
+	return slices.Index(searchMatches, match)
+}
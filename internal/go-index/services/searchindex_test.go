@@ -0,0 +1,124 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+	"github.com/sirupsen/logrus"
+)
+
+var errScanFailed = errors.New("scan failed")
+
+// failingDiscovery fails every listing once fail is set.
+type failingDiscovery struct {
+	services.DiscoveryService
+	fail bool
+}
+
+func (d *failingDiscovery) ListModules(ctx context.Context, page providers.Page) (providers.Listing, error) {
+	if d.fail {
+		return providers.Listing{}, errScanFailed
+	}
+
+	return d.DiscoveryService.ListModules(ctx, page) //nolint:wrapcheck
+}
+
+func searchSource(t *testing.T, repository string, paths ...string) services.SearchSource {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for _, path := range paths {
+		filename := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte("content"), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	logger := logrus.New()
+	provider := providers.NewLocalProvider(dir, nil)
+
+	return services.SearchSource{
+		Repository: repository,
+		Discovery:  services.NewDiscoveryService(provider, logger),
+		Versions:   services.NewService(provider, versioning.SemverScheme{}, services.InvalidVersionSkip, logger),
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	t.Parallel()
+
+	files := searchSource(t, "files",
+		"fe/app1/app1-1.0.0.txt", "fe/app1/app1-1.2.0.txt", "fe/web-app/web-app-2.0.0.txt", "be/api/api-nightly.txt")
+	charts := searchSource(t, "charts", "helm/app/app-0.1.0.tgz")
+	discovery := &failingDiscovery{DiscoveryService: charts.Discovery, fail: false}
+	charts.Discovery = discovery
+
+	index := services.NewSearchIndex([]services.SearchSource{files, charts}, 0, logrus.New())
+	defer index.Close()
+
+	all := func(string) bool { return true }
+
+	if results, indexedAt := index.Search("app", 10, all); len(results) != 0 || !indexedAt.IsZero() {
+		t.Fatalf("Search before Refresh returned %v, %v; want nothing", results, indexedAt)
+	}
+
+	index.Refresh(context.Background())
+
+	results, indexedAt := index.Search("APP", 10, all)
+	if indexedAt.IsZero() {
+		t.Error("Search after Refresh reported the index as not built")
+	}
+
+	want := []services.SearchResult{
+		{Repository: "charts", Module: "helm", Artifact: "app", Latest: "0.1.0", Match: services.SearchMatchExact},
+		{Repository: "files", Module: "fe", Artifact: "app1", Latest: "1.2.0", Match: services.SearchMatchPrefix},
+		{Repository: "files", Module: "fe", Artifact: "web-app", Latest: "2.0.0", Match: services.SearchMatchSubstring},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Search returned %+v; want %+v", results, want)
+	}
+
+	for index := range want {
+		if results[index] != want[index] {
+			t.Errorf("Search result %d is %+v; want %+v", index, results[index], want[index])
+		}
+	}
+
+	if results, _ := index.Search("be/a", 10, all); len(results) != 1 || results[0].Artifact != "api" ||
+		results[0].Latest != "" {
+		t.Errorf("Search by path returned %+v; want be/api without a latest version", results)
+	}
+
+	if results, _ := index.Search("app", 1, func(repository string) bool { return repository == "files" }); len(results) != 1 ||
+		results[0].Artifact != "app1" {
+		t.Errorf("Search limited to files returned %+v; want only app1", results)
+	}
+
+	discovery.fail = true
+
+	index.Refresh(context.Background())
+
+	if results, _ := index.Search("helm", 10, all); len(results) != 1 {
+		t.Errorf("Search after a failed scan returned %+v; want the previous entries", results)
+	}
+
+	successor := services.NewSearchIndex([]services.SearchSource{files}, 0, logrus.New())
+	defer successor.Close()
+
+	successor.Inherit(index)
+
+	if results, indexedAt := successor.Search("app", 10, all); len(results) != 2 || indexedAt.IsZero() {
+		t.Errorf("Search of a successor returned %+v, %v; want the entries of files only", results, indexedAt)
+	}
+}
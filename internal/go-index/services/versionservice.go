@@ -1,70 +1,251 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/blang/semver"
 	"github.com/mauhlik/go-index/internal/go-index/providers"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	ErrNoMatchingVersion   = errors.New("no version matches the constraint")
+	ErrArtifactNotFound    = providers.ErrArtifactNotFound
+	ErrProviderUnavailable = providers.ErrProviderUnavailable
+	// ErrInvalidStoredVersion is a version in storage rejected by the strict invalid version policy; it is
+	// the repository's content that is wrong, not the request.
+	ErrInvalidStoredVersion = errors.New("repository contains an invalid version")
+)
+
+type Resolution struct {
+	Version string   `json:"version"`
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+type VersionDetails struct {
+	Module   string               `json:"module"`
+	Artifact string               `json:"artifact"`
+	Version  string               `json:"version"`
+	Files    []providers.Artifact `json:"files"`
+}
+
 type VersionService interface {
-	GetVersions(moduleName, artifactName string) ([]string, error)
-	GetLatestVersion(moduleName, artifactName string) (string, error)
+	GetVersions(ctx context.Context, moduleName, artifactName string) ([]string, error)
+	GetVersionDetails(ctx context.Context, moduleName, artifactName, version string) (*VersionDetails, error)
+	GetLatestVersion(ctx context.Context, moduleName, artifactName string) (*Resolution, error)
+	ResolveVersion(ctx context.Context, moduleName, artifactName, constraint string) (*Resolution, error)
 }
 
+// SkipObserver is told how many versions of a listing were skipped because they did not parse.
+type SkipObserver func(count int)
+
 type VersionServiceImpl struct {
 	provider providers.Provider
+	scheme   versioning.Scheme
+	policy   InvalidVersionPolicy
 	logger   *logrus.Logger
+	observer SkipObserver
 }
 
-func NewService(provider providers.Provider, logger *logrus.Logger) *VersionServiceImpl {
-	return &VersionServiceImpl{provider: provider, logger: logger}
+func NewService(provider providers.Provider, scheme versioning.Scheme, policy InvalidVersionPolicy,
+	logger *logrus.Logger) *VersionServiceImpl {
+	return &VersionServiceImpl{provider: provider, scheme: scheme, policy: policy, logger: logger, observer: nil}
 }
 
-func (vs *VersionServiceImpl) GetVersions(moduleName, artifactName string) ([]string, error) {
+// SetSkipObserver reports the versions skipped by every listing parsed from now on, including those
+// parsed for search.
+func (vs *VersionServiceImpl) SetSkipObserver(observer SkipObserver) {
+	vs.observer = observer
+}
+
+func (vs *VersionServiceImpl) GetVersions(ctx context.Context, moduleName, artifactName string) ([]string, error) {
 	vs.logger.Infof("Fetching versions for module: %s, artifact: %s", moduleName, artifactName)
-	versions, err := vs.provider.GetVersions(moduleName, artifactName)
 
+	return vs.listVersions(ctx, moduleName, artifactName)
+}
+
+func (vs *VersionServiceImpl) GetVersionDetails(ctx context.Context, moduleName, artifactName,
+	version string) (*VersionDetails, error) {
+	vs.logger.Infof("Fetching details for module: %s, artifact: %s, version: %s", moduleName, artifactName, version)
+
+	// A strict repository cannot hold invalid versions, so asking for one is the client's mistake.
+	if vs.policy == InvalidVersionStrict {
+		if _, err := vs.scheme.Parse(version); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	artifacts, err := vs.provider.ListArtifacts(ctx, moduleName, artifactName, version)
 	if err != nil {
-		vs.logger.WithError(err).Errorf("Failed to get versions for %s/%s", moduleName, artifactName)
+		vs.logProviderError(err, "Failed to list artifacts for %s/%s@%s", moduleName, artifactName, version)
 
-		return nil, fmt.Errorf("failed to get versions from provider: %w", err)
+		return nil, fmt.Errorf("failed to list artifacts from provider: %w", err)
 	}
 
-	return versions, nil
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s@%s", ErrVersionNotFound, moduleName, artifactName, version)
+	}
+
+	if err := vs.addChecksums(ctx, artifacts); err != nil {
+		return nil, err
+	}
+
+	return &VersionDetails{Module: moduleName, Artifact: artifactName, Version: version, Files: artifacts}, nil
+}
+
+// addChecksums fills in the checksums a Checksummer leaves out of its listings. Listings may be shared
+// with a cache, so each artifact gets a new map rather than an updated one.
+func (vs *VersionServiceImpl) addChecksums(ctx context.Context, artifacts []providers.Artifact) error {
+	checksummer, ok := vs.provider.(providers.Checksummer)
+	if !ok {
+		return nil
+	}
+
+	for index := range artifacts {
+		checksums, err := checksummer.Checksums(ctx, artifacts[index])
+		if err != nil {
+			vs.logProviderError(err, "Failed to checksum %s", artifacts[index].Path)
+
+			return fmt.Errorf("failed to checksum %s: %w", artifacts[index].Name, err)
+		}
+
+		artifacts[index].Checksums = checksums
+	}
+
+	return nil
 }
 
-func (vs *VersionServiceImpl) GetLatestVersion(moduleName, artifactName string) (string, error) {
+func (vs *VersionServiceImpl) GetLatestVersion(ctx context.Context, moduleName, artifactName string) (*Resolution, error) {
 	vs.logger.Infof("Fetching latest version for module: %s, artifact: %s", moduleName, artifactName)
-	versions, err := vs.provider.GetVersions(moduleName, artifactName)
 
+	versions, err := vs.listVersions(ctx, moduleName, artifactName)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedVersions, skipped, err := vs.parseVersions(moduleName, artifactName, versions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parsedVersions) == 0 {
+		vs.logger.Infof("No valid versions found for %s/%s", moduleName, artifactName)
+
+		return nil, fmt.Errorf("%w: %s/%s has no valid versions, skipped %s", ErrVersionNotFound,
+			moduleName, artifactName, strings.Join(skipped, ", "))
+	}
+
+	return &Resolution{Version: vs.scheme.Latest(parsedVersions).String(), Skipped: skipped}, nil
+}
+
+func (vs *VersionServiceImpl) ResolveVersion(ctx context.Context, moduleName, artifactName,
+	constraint string) (*Resolution, error) {
+	vs.logger.Infof("Resolving version for module: %s, artifact: %s, constraint: %s", moduleName, artifactName, constraint)
+
+	matches, err := vs.scheme.ParseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := vs.listVersions(ctx, moduleName, artifactName)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedVersions, skipped, err := vs.parseVersions(moduleName, artifactName, versions)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []versioning.Version
+
+	for _, version := range parsedVersions {
+		if matches(version) {
+			candidates = append(candidates, version)
+		}
+	}
+
+	if len(candidates) == 0 {
+		vs.logger.Infof("No version of %s/%s matches %s", moduleName, artifactName, constraint)
+
+		return nil, fmt.Errorf("%w %q for %s/%s", ErrNoMatchingVersion, constraint, moduleName, artifactName)
+	}
+
+	best := candidates[0]
+
+	for _, candidate := range candidates[1:] {
+		if vs.scheme.Compare(candidate, best) > 0 {
+			best = candidate
+		}
+	}
+
+	return &Resolution{Version: best.String(), Skipped: skipped}, nil
+}
+
+// listVersions reports an artifact without any version as ErrArtifactNotFound, since storage such as S3
+// cannot tell it from one that was never published.
+func (vs *VersionServiceImpl) listVersions(ctx context.Context, moduleName, artifactName string) ([]string, error) {
+	versions, err := vs.provider.GetVersions(ctx, moduleName, artifactName)
 	if err != nil {
-		vs.logger.WithError(err).Errorf("Failed to get versions for %s/%s", moduleName, artifactName)
+		vs.logProviderError(err, "Failed to get versions for %s/%s", moduleName, artifactName)
 
-		return "", fmt.Errorf("failed to get versions: %w", err)
+		return nil, fmt.Errorf("failed to get versions from provider: %w", err)
 	}
 
 	if len(versions) == 0 {
 		vs.logger.Infof("No versions found for %s/%s", moduleName, artifactName)
 
-		return "", nil
+		return nil, fmt.Errorf("%w: %s/%s", ErrArtifactNotFound, moduleName, artifactName)
+	}
+
+	return versions, nil
+}
+
+// logProviderError logs missing artifacts at info level; they are the client's mistake, not a failure.
+func (vs *VersionServiceImpl) logProviderError(err error, format string, args ...interface{}) {
+	if errors.Is(err, ErrArtifactNotFound) {
+		vs.logger.WithError(err).Infof(format, args...)
+
+		return
 	}
 
-	semVersions := make([]semver.Version, len(versions))
+	vs.logger.WithError(err).Errorf(format, args...)
+}
+
+func (vs *VersionServiceImpl) parseVersions(moduleName, artifactName string,
+	versions []string) ([]versioning.Version, []string, error) {
+	parsedVersions := make([]versioning.Version, 0, len(versions))
+
+	var skipped []string
+
+	for _, version := range versions {
+		parsedVersion, err := vs.scheme.Parse(version)
+		if err != nil && vs.policy == InvalidVersionCoerce {
+			parsedVersion, err = vs.scheme.Coerce(version)
+		}
 
-	for index, version := range versions {
-		semVersion, err := semver.Parse(version)
 		if err != nil {
-			vs.logger.WithError(err).Errorf("Failed to parse version: %s", version)
+			if vs.policy == InvalidVersionStrict {
+				vs.logger.WithError(err).Errorf("Failed to parse version: %s", version)
+
+				return nil, nil, fmt.Errorf("%w: %w", ErrInvalidStoredVersion, err)
+			}
 
-			return "", fmt.Errorf("failed to parse version: %w", err)
+			vs.logger.WithError(err).Warnf("Skipping invalid version %s of %s/%s", version, moduleName, artifactName)
+			skipped = append(skipped, version)
+
+			continue
 		}
 
-		semVersions[index] = semVersion
+		parsedVersions = append(parsedVersions, parsedVersion)
 	}
 
-	semver.Sort(semVersions)
+	if len(skipped) > 0 && vs.observer != nil {
+		vs.observer(len(skipped))
+	}
 
-	return semVersions[len(semVersions)-1].String(), nil
+	return parsedVersions, skipped, nil
 }
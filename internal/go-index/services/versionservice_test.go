@@ -1,11 +1,19 @@
 package services_test
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/mauhlik/go-index/internal/go-index/mocks"
+	"github.com/mauhlik/go-index/internal/go-index/providers"
 	"github.com/mauhlik/go-index/internal/go-index/services"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,13 +25,13 @@ func TestVersionServiceGetVersions(t *testing.T) {
 
 	mockProvider := mocks.NewMockProvider(mockCtrl)
 	logger := logrus.New()
-	service := services.NewService(mockProvider, logger)
+	service := services.NewService(mockProvider, versioning.SemverScheme{}, services.InvalidVersionSkip, logger)
 
 	moduleName := "fe"
 	artifactName := "app1"
 	expectedVersions := []string{"0.0.0", "0.0.1", "1.0.0", "2.0.0"}
 
-	gotVersions, err := service.GetVersions(moduleName, artifactName)
+	gotVersions, err := service.GetVersions(context.Background(), moduleName, artifactName)
 	if err != nil {
 		t.Fatalf("GetVersions returned an error: %v", err)
 	}
@@ -47,18 +55,213 @@ func TestVersionServiceGetLatestVersion(t *testing.T) {
 
 	mockProvider := mocks.NewMockProvider(mockCtrl)
 	logger := logrus.New()
-	service := services.NewService(mockProvider, logger)
+	service := services.NewService(mockProvider, versioning.SemverScheme{}, services.InvalidVersionSkip, logger)
 
 	moduleName := "fe"
 	artifactName := "app1"
 	expectedLatestVersion := "2.0.0"
 
-	gotLatestVersion, err := service.GetLatestVersion(moduleName, artifactName)
+	gotLatestVersion, err := service.GetLatestVersion(context.Background(), moduleName, artifactName)
 	if err != nil {
 		t.Fatalf("GetLatestVersion returned an error: %v", err)
 	}
 
-	if gotLatestVersion != expectedLatestVersion {
-		t.Errorf("GetLatestVersion returned %q; want %q", gotLatestVersion, expectedLatestVersion)
+	if gotLatestVersion.Version != expectedLatestVersion {
+		t.Errorf("GetLatestVersion returned %q; want %q", gotLatestVersion.Version, expectedLatestVersion)
+	}
+}
+
+func TestVersionServiceResolveVersion(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockProvider := mocks.NewMockProvider(mockCtrl)
+	logger := logrus.New()
+	service := services.NewService(mockProvider, versioning.SemverScheme{}, services.InvalidVersionSkip, logger)
+
+	tests := []struct {
+		constraint  string
+		expected    string
+		expectedErr error
+	}{
+		{"^1.0", "1.0.0", nil},
+		{"<1.0.0", "0.0.1", nil},
+		{"*", "2.0.0", nil},
+		{"^3", "", services.ErrNoMatchingVersion},
+		{"=>1", "", versioning.ErrInvalidConstraint},
+	}
+
+	for _, testCase := range tests {
+		got, err := service.ResolveVersion(context.Background(), "fe", "app1", testCase.constraint)
+		if !errors.Is(err, testCase.expectedErr) {
+			t.Errorf("ResolveVersion(%q) returned error %v; want %v", testCase.constraint, err, testCase.expectedErr)
+		}
+
+		if err == nil && got.Version != testCase.expected {
+			t.Errorf("ResolveVersion(%q) = %q; want %q", testCase.constraint, got.Version, testCase.expected)
+		}
+	}
+}
+
+func TestVersionServiceInvalidVersionPolicies(t *testing.T) {
+	t.Parallel()
+
+	versions := []string{"1.0.0", "v1.2", "latest", "1.1.0"}
+	tests := []struct {
+		policy          services.InvalidVersionPolicy
+		expectedLatest  string
+		expectedSkipped []string
+		expectError     bool
+	}{
+		{services.InvalidVersionSkip, "1.1.0", []string{"v1.2", "latest"}, false},
+		{services.InvalidVersionCoerce, "1.2.0", []string{"latest"}, false},
+		{services.InvalidVersionStrict, "", nil, true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(string(testCase.policy), func(t *testing.T) {
+			t.Parallel()
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockProvider := mocks.NewMockProviderWithVersions(mockCtrl, versions)
+			service := services.NewService(mockProvider, versioning.SemverScheme{}, testCase.policy, logrus.New())
+
+			observed := 0
+			service.SetSkipObserver(func(count int) { observed += count })
+
+			got, err := service.GetLatestVersion(context.Background(), "fe", "app1")
+			if testCase.expectError {
+				if err == nil {
+					t.Fatalf("GetLatestVersion returned %+v; want an error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("GetLatestVersion returned an error: %v", err)
+			}
+
+			if got.Version != testCase.expectedLatest {
+				t.Errorf("GetLatestVersion returned %q; want %q", got.Version, testCase.expectedLatest)
+			}
+
+			if strings.Join(got.Skipped, ",") != strings.Join(testCase.expectedSkipped, ",") {
+				t.Errorf("GetLatestVersion skipped %v; want %v", got.Skipped, testCase.expectedSkipped)
+			}
+
+			if observed != len(testCase.expectedSkipped) {
+				t.Errorf("SkipObserver was told about %d versions; want %d", observed, len(testCase.expectedSkipped))
+			}
+		})
+	}
+}
+
+func TestParseInvalidVersionPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := services.ParseInvalidVersionPolicy("")
+	if err != nil || policy != services.InvalidVersionSkip {
+		t.Errorf("ParseInvalidVersionPolicy(\"\") = %q, %v; want %q", policy, err, services.InvalidVersionSkip)
+	}
+
+	if _, err := services.ParseInvalidVersionPolicy("lenient"); !errors.Is(err, services.ErrUnknownInvalidVersionPolicy) {
+		t.Errorf("ParseInvalidVersionPolicy returned %v; want %v", err, services.ErrUnknownInvalidVersionPolicy)
+	}
+}
+
+func TestVersionServiceWithScheme(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockProvider := mocks.NewMockProviderWithVersions(mockCtrl, []string{"1.0", "1.1rc1", "1.0.post2", "0.9"})
+	service := services.NewService(mockProvider, versioning.PEP440Scheme{}, services.InvalidVersionSkip, logrus.New())
+
+	latest, err := service.GetLatestVersion(context.Background(), "fe", "app1")
+	if err != nil {
+		t.Fatalf("GetLatestVersion returned an error: %v", err)
+	}
+
+	if latest.Version != "1.0.post2" {
+		t.Errorf("GetLatestVersion returned %q; want %q", latest.Version, "1.0.post2")
+	}
+
+	resolved, err := service.ResolveVersion(context.Background(), "fe", "app1", "<1.0")
+	if err != nil {
+		t.Fatalf("ResolveVersion returned an error: %v", err)
+	}
+
+	if resolved.Version != "0.9" {
+		t.Errorf("ResolveVersion returned %q; want %q", resolved.Version, "0.9")
+	}
+}
+
+func TestVersionServiceGetVersionDetails(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockProvider := mocks.NewMockProvider(mockCtrl)
+	service := services.NewService(mockProvider, versioning.SemverScheme{}, services.InvalidVersionSkip, logrus.New())
+
+	details, err := service.GetVersionDetails(context.Background(), "fe", "app1", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetVersionDetails returned an error: %v", err)
+	}
+
+	if details.Version != "1.0.0" || len(details.Files) != 1 || details.Files[0].Name != "app1-1.0.0.txt" {
+		t.Errorf("GetVersionDetails returned %+v; want a single app1-1.0.0.txt file", details)
+	}
+
+	if _, err := service.GetVersionDetails(context.Background(), "fe", "app1", "9.9.9"); !errors.Is(err, services.ErrVersionNotFound) {
+		t.Errorf("GetVersionDetails returned %v; want %v", err, services.ErrVersionNotFound)
+	}
+}
+
+func TestVersionServiceGetVersionDetailsChecksums(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "fe", "app1", "app1-1.0.0.txt")
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	if err := os.WriteFile(filename, []byte("hello"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	provider := providers.NewCachingProvider(providers.NewLocalProvider(dir, providers.DefaultLayout{}), "test",
+		time.Minute, time.Minute, 0, logrus.New())
+	defer provider.Close()
+
+	service := services.NewService(provider, versioning.SemverScheme{}, services.InvalidVersionSkip, logrus.New())
+
+	details, err := service.GetVersionDetails(context.Background(), "fe", "app1", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetVersionDetails returned an error: %v", err)
+	}
+
+	expectedChecksum := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if len(details.Files) != 1 || details.Files[0].Checksums["sha256"] != expectedChecksum {
+		t.Errorf("GetVersionDetails returned %+v; want one file with sha256 %s", details.Files, expectedChecksum)
+	}
+
+	// The listing behind downloads is cached without the checksums added for the details.
+	artifacts, err := provider.ListArtifacts(context.Background(), "fe", "app1", "1.0.0")
+	if err != nil {
+		t.Fatalf("ListArtifacts returned an error: %v", err)
+	}
+
+	if len(artifacts) != 1 || artifacts[0].Checksums != nil {
+		t.Errorf("ListArtifacts returned %+v; want one file without checksums", artifacts)
 	}
 }
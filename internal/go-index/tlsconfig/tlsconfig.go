@@ -0,0 +1,206 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultReloadInterval = 10 * time.Second
+
+	ClientAuthRequire  = "require"
+	ClientAuthOptional = "optional"
+)
+
+var (
+	ErrMissingKeyPair    = errors.New("both a certificate and a key file are required")
+	ErrInvalidVersion    = errors.New("invalid TLS version")
+	ErrInvalidClientAuth = errors.New("invalid client auth mode")
+	ErrNoClientCAs       = errors.New("no certificates found in client CA file")
+)
+
+type Options struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables mutual TLS; client certificates must chain to one of its certificates.
+	ClientCAFile string
+	// ClientAuth is require (default) or optional, which lets clients without a certificate connect.
+	ClientAuth string
+	// MinVersion is 1.2 (default) or 1.3.
+	MinVersion string
+	// ReloadInterval is how often the files are checked for changes during handshakes.
+	ReloadInterval time.Duration
+}
+
+// New returns a server configuration that re-reads the certificate, key and client CAs when their files
+// change, so renewed certificates are picked up without a restart. A failed reload keeps the previous files.
+func New(options Options, logger *logrus.Logger) (*tls.Config, error) {
+	if options.CertFile == "" || options.KeyFile == "" {
+		return nil, ErrMissingKeyPair
+	}
+
+	minVersion, err := parseVersion(options.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth, err := parseClientAuth(options.ClientAuth, options.ClientCAFile != "")
+	if err != nil {
+		return nil, err
+	}
+
+	if options.ReloadInterval <= 0 {
+		options.ReloadInterval = DefaultReloadInterval
+	}
+
+	files := &reloader{
+		options:     options,
+		logger:      logger,
+		mutex:       sync.Mutex{},
+		certificate: nil,
+		clientCAs:   nil,
+		stamp:       "",
+		checkedAt:   time.Time{},
+	}
+
+	if err := files.load(); err != nil {
+		return nil, err
+	}
+
+	base := &tls.Config{ //nolint:exhaustruct
+		MinVersion: minVersion,
+		ClientAuth: clientAuth,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		certificate, clientCAs := files.current()
+
+		config := base.Clone()
+		config.GetConfigForClient = nil
+		config.Certificates = []tls.Certificate{*certificate}
+		config.ClientCAs = clientCAs
+
+		return config, nil
+	}
+
+	return base, nil
+}
+
+type reloader struct {
+	options Options
+	logger  *logrus.Logger
+
+	mutex       sync.Mutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	stamp       string
+	checkedAt   time.Time
+}
+
+func (r *reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if time.Since(r.checkedAt) >= r.options.ReloadInterval {
+		r.checkedAt = time.Now()
+
+		if stamp, err := r.fileStamp(); err != nil || stamp != r.stamp {
+			if err := r.load(); err != nil {
+				r.logger.WithError(err).Warn("Failed to reload TLS certificates, keeping the previous ones")
+			} else {
+				r.logger.Infof("Reloaded TLS certificate %s", r.options.CertFile)
+			}
+		}
+	}
+
+	return r.certificate, r.clientCAs
+}
+
+func (r *reloader) load() error {
+	stamp, err := r.fileStamp()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.options.CertFile, r.options.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+
+	if r.options.ClientCAFile != "" {
+		data, err := os.ReadFile(r.options.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("%w: %s", ErrNoClientCAs, r.options.ClientCAFile)
+		}
+	}
+
+	r.certificate, r.clientCAs, r.stamp = &certificate, clientCAs, stamp
+
+	return nil
+}
+
+// fileStamp identifies the current contents of the files by their size and modification time.
+func (r *reloader) fileStamp() (string, error) {
+	var stamp strings.Builder
+
+	for _, filename := range []string{r.options.CertFile, r.options.KeyFile, r.options.ClientCAFile} {
+		if filename == "" {
+			continue
+		}
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			return "", fmt.Errorf("failed to stat %s: %w", filename, err)
+		}
+
+		fmt.Fprintf(&stamp, "%d:%d;", info.Size(), info.ModTime().UnixNano())
+	}
+
+	return stamp.String(), nil
+}
+
+func parseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrInvalidVersion, version)
+	}
+}
+
+func parseClientAuth(mode string, hasClientCAs bool) (tls.ClientAuthType, error) {
+	if !hasClientCAs {
+		if mode != "" {
+			return tls.NoClientCert, fmt.Errorf("%w: %s requires a client CA file", ErrInvalidClientAuth, mode)
+		}
+
+		return tls.NoClientCert, nil
+	}
+
+	switch mode {
+	case "", ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("%w: %s", ErrInvalidClientAuth, mode)
+	}
+}
@@ -0,0 +1,266 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mauhlik/go-index/internal/go-index/tlsconfig"
+	"github.com/sirupsen/logrus"
+)
+
+type issuer struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newIssuer(t *testing.T) *issuer {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse CA certificate: %v", err)
+	}
+
+	return &issuer{certificate: certificate, key: key}
+}
+
+// issue writes a certificate for commonName and its key as PEM files and returns their paths.
+func (i *issuer) issue(t *testing.T, dir, commonName string, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, i.certificate, &key.PublicKey, i.key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, commonName+".crt")
+	keyFile := filepath.Join(dir, commonName+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "PRIVATE KEY", keyDER)
+
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, filename, blockType string, der []byte) {
+	t.Helper()
+
+	if err := os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", filename, err)
+	}
+}
+
+// handshake connects a client to a server using config and returns the server side connection state.
+func handshake(t *testing.T, config *tls.Config, client *tls.Config) (tls.ConnectionState, error) {
+	t.Helper()
+
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+
+	clientErr := make(chan error, 1)
+
+	go func() {
+		conn := tls.Client(clientConn, client)
+
+		err := conn.Handshake()
+		if err == nil {
+			// Under TLS 1.3 a missing client certificate is only reported after the client's handshake.
+			_, err = conn.Read(make([]byte, 1))
+		}
+
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+
+		clientErr <- err
+	}()
+
+	server := tls.Server(serverConn, config)
+	err := server.Handshake()
+	state := server.ConnectionState()
+
+	server.Close()
+
+	if clientErr := <-clientErr; err == nil {
+		err = clientErr
+	}
+
+	return state, err
+}
+
+func TestMutualTLS(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	authority := newIssuer(t)
+	certFile, keyFile := authority.issue(t, dir, "localhost", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := authority.issue(t, dir, "ci", x509.ExtKeyUsageClientAuth)
+	caFile := filepath.Join(dir, "ca.crt")
+	writePEM(t, caFile, "CERTIFICATE", authority.certificate.Raw)
+
+	config, err := tlsconfig.New(tlsconfig.Options{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ClientCAFile:   caFile,
+		ClientAuth:     "",
+		MinVersion:     "1.3",
+		ReloadInterval: 0,
+	}, logrus.New())
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.certificate)
+
+	keyPair, err := tls.LoadX509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatalf("Failed to load client certificate: %v", err)
+	}
+
+	state, err := handshake(t, config, &tls.Config{
+		RootCAs:      roots,
+		ServerName:   "localhost",
+		Certificates: []tls.Certificate{keyPair},
+	})
+	if err != nil {
+		t.Fatalf("Handshake failed: %v", err)
+	}
+
+	if state.Version != tls.VersionTLS13 || state.VerifiedChains[0][0].Subject.CommonName != "ci" {
+		t.Errorf("Handshake negotiated %x for %v; want TLS 1.3 and client ci", state.Version, state.VerifiedChains)
+	}
+
+	if _, err := handshake(t, config, &tls.Config{RootCAs: roots, ServerName: "localhost"}); err == nil {
+		t.Error("Handshake without a client certificate succeeded; want it rejected")
+	}
+}
+
+func TestCertificateReload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	authority := newIssuer(t)
+	certFile, keyFile := authority.issue(t, dir, "localhost", x509.ExtKeyUsageServerAuth)
+
+	config, err := tlsconfig.New(tlsconfig.Options{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ClientCAFile:   "",
+		ClientAuth:     "",
+		MinVersion:     "",
+		ReloadInterval: time.Nanosecond,
+	}, logrus.New())
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+
+	serial := func() *big.Int {
+		t.Helper()
+
+		current, err := config.GetConfigForClient(nil)
+		if err != nil {
+			t.Fatalf("GetConfigForClient returned an error: %v", err)
+		}
+
+		return current.Certificates[0].Leaf.SerialNumber
+	}
+
+	before := serial()
+
+	renewedCert, renewedKey := authority.issue(t, t.TempDir(), "localhost", x509.ExtKeyUsageServerAuth)
+	for source, target := range map[string]string{renewedCert: certFile, renewedKey: keyFile} {
+		if err := os.Rename(source, target); err != nil {
+			t.Fatalf("Failed to replace %s: %v", target, err)
+		}
+	}
+
+	if after := serial(); after.Cmp(before) == 0 {
+		t.Error("Certificate was not reloaded after the files changed")
+	}
+
+	if err := os.WriteFile(keyFile, []byte("broken"), 0600); err != nil {
+		t.Fatalf("Failed to corrupt key: %v", err)
+	}
+
+	if serial() == nil {
+		t.Error("A failed reload dropped the previous certificate")
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		options tlsconfig.Options
+		want    error
+	}{
+		{"missing key", tlsconfig.Options{CertFile: "cert.pem"}, tlsconfig.ErrMissingKeyPair},
+		{"version", tlsconfig.Options{CertFile: "c", KeyFile: "k", MinVersion: "1.1"}, tlsconfig.ErrInvalidVersion},
+		{"client auth without CA", tlsconfig.Options{CertFile: "c", KeyFile: "k", ClientAuth: "optional"},
+			tlsconfig.ErrInvalidClientAuth},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := tlsconfig.New(testCase.options, logrus.New()); !errors.Is(err, testCase.want) {
+				t.Errorf("New returned %v; want %v", err, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package versioning
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	calVerMinYearDigits = 2
+	calVerMaxParts      = 4
+)
+
+type CalVerVersion struct {
+	original string
+	parts    []uint64
+	modifier string
+}
+
+func (v CalVerVersion) String() string {
+	return v.original
+}
+
+func (v CalVerVersion) IsPrerelease() bool {
+	return v.modifier != ""
+}
+
+// CalVerScheme accepts dot separated numeric versions led by a year (2024.10.3, 24.04, 2024.10.3-beta).
+type CalVerScheme struct{}
+
+func (CalVerScheme) Name() string {
+	return SchemeCalVer
+}
+
+//nolint:ireturn
+func (CalVerScheme) Parse(value string) (Version, error) {
+	core, modifier, _ := strings.Cut(value, "-")
+	fields := strings.Split(core, ".")
+
+	if len(fields) > calVerMaxParts || len(fields[0]) < calVerMinYearDigits {
+		return nil, fmt.Errorf("%w %q: not a calendar version", ErrInvalidVersion, value)
+	}
+
+	parts := make([]uint64, len(fields))
+
+	for index, field := range fields {
+		part, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidVersion, value, err)
+		}
+
+		parts[index] = part
+	}
+
+	return CalVerVersion{original: value, parts: parts, modifier: modifier}, nil
+}
+
+//nolint:ireturn
+func (s CalVerScheme) Coerce(value string) (Version, error) {
+	return s.Parse(strings.TrimPrefix(strings.TrimPrefix(value, "v"), "V"))
+}
+
+func (CalVerScheme) Compare(a, b Version) int {
+	left, right := asCalVer(a), asCalVer(b)
+
+	if result := compareReleases(left.parts, right.parts); result != 0 {
+		return result
+	}
+
+	switch {
+	case left.modifier == right.modifier:
+		return 0
+	case left.modifier == "":
+		return 1
+	case right.modifier == "":
+		return -1
+	default:
+		return strings.Compare(left.modifier, right.modifier)
+	}
+}
+
+func (s CalVerScheme) ParseConstraint(constraint string) (Constraint, error) {
+	return parseComparatorConstraint(s, constraint)
+}
+
+//nolint:ireturn
+func (s CalVerScheme) Latest(versions []Version) Version {
+	return latestOf(s, versions, true)
+}
+
+func asCalVer(version Version) CalVerVersion {
+	calVerVersion, _ := version.(CalVerVersion)
+
+	return calVerVersion
+}
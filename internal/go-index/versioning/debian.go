@@ -0,0 +1,164 @@
+package versioning
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type DebianVersion struct {
+	original string
+	epoch    uint64
+	upstream string
+	revision string
+}
+
+func (v DebianVersion) String() string {
+	return v.original
+}
+
+func (v DebianVersion) IsPrerelease() bool {
+	return strings.Contains(v.upstream, "~")
+}
+
+// DebianScheme implements dpkg version ordering: [epoch:]upstream[-revision], where "~" sorts before anything.
+type DebianScheme struct{}
+
+func (DebianScheme) Name() string {
+	return SchemeDebian
+}
+
+//nolint:ireturn
+func (DebianScheme) Parse(value string) (Version, error) {
+	version := DebianVersion{original: value, epoch: 0, upstream: value, revision: ""}
+
+	if epoch, rest, ok := strings.Cut(value, ":"); ok {
+		number, err := strconv.ParseUint(epoch, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: invalid epoch: %w", ErrInvalidVersion, value, err)
+		}
+
+		version.epoch, version.upstream = number, rest
+	}
+
+	if index := strings.LastIndex(version.upstream, "-"); index >= 0 {
+		version.upstream, version.revision = version.upstream[:index], version.upstream[index+1:]
+	}
+
+	if version.upstream == "" || !unicode.IsDigit(rune(version.upstream[0])) {
+		return nil, fmt.Errorf("%w %q: upstream version must start with a digit", ErrInvalidVersion, value)
+	}
+
+	for _, char := range version.upstream + version.revision {
+		if !unicode.IsDigit(char) && !unicode.IsLetter(char) && !strings.ContainsRune(".+~-:", char) {
+			return nil, fmt.Errorf("%w %q: unexpected character %q", ErrInvalidVersion, value, char)
+		}
+	}
+
+	return version, nil
+}
+
+//nolint:ireturn
+func (s DebianScheme) Coerce(value string) (Version, error) {
+	return s.Parse(strings.TrimPrefix(strings.TrimPrefix(value, "v"), "V"))
+}
+
+func (DebianScheme) Compare(a, b Version) int {
+	left, right := asDebian(a), asDebian(b)
+
+	if result := cmp.Compare(left.epoch, right.epoch); result != 0 {
+		return result
+	}
+
+	if result := compareDebianPart(left.upstream, right.upstream); result != 0 {
+		return result
+	}
+
+	return compareDebianPart(left.revision, right.revision)
+}
+
+func (s DebianScheme) ParseConstraint(constraint string) (Constraint, error) {
+	return parseComparatorConstraint(s, constraint)
+}
+
+//nolint:ireturn
+func (s DebianScheme) Latest(versions []Version) Version {
+	return latestOf(s, versions, true)
+}
+
+// compareDebianPart is dpkg's verrevcmp: alternating non-digit and digit runs.
+func compareDebianPart(left, right string) int {
+	for left != "" || right != "" {
+		var leftText, rightText string
+
+		leftText, left = splitRun(left, false)
+		rightText, right = splitRun(right, false)
+
+		if result := compareDebianText(leftText, rightText); result != 0 {
+			return result
+		}
+
+		var leftDigits, rightDigits string
+
+		leftDigits, left = splitRun(left, true)
+		rightDigits, right = splitRun(right, true)
+
+		if result := cmp.Compare(parseNumber(leftDigits), parseNumber(rightDigits)); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+func splitRun(value string, digits bool) (string, string) {
+	index := strings.IndexFunc(value, func(char rune) bool { return unicode.IsDigit(char) != digits })
+	if index < 0 {
+		return value, ""
+	}
+
+	return value[:index], value[index:]
+}
+
+func compareDebianText(left, right string) int {
+	for index := range max(len(left), len(right)) {
+		var leftChar, rightChar byte
+
+		if index < len(left) {
+			leftChar = left[index]
+		}
+
+		if index < len(right) {
+			rightChar = right[index]
+		}
+
+		if result := cmp.Compare(debianOrder(leftChar), debianOrder(rightChar)); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+func debianOrder(char byte) int {
+	const nonLetterOffset = 256
+
+	switch {
+	case char == '~':
+		return -1
+	case char == 0:
+		return 0
+	case unicode.IsLetter(rune(char)):
+		return int(char)
+	default:
+		return int(char) + nonLetterOffset
+	}
+}
+
+func asDebian(version Version) DebianVersion {
+	debianVersion, _ := version.(DebianVersion)
+
+	return debianVersion
+}
@@ -0,0 +1,200 @@
+package versioning
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const mavenUnknownQualifierRank = 7
+
+// mavenQualifierRanks follows Maven's ComparableVersion ordering; "" is a plain release.
+var mavenQualifierRanks = map[string]int{ //nolint:gochecknoglobals
+	"alpha":     0,
+	"beta":      1,
+	"milestone": 2, //nolint:mnd
+	"rc":        3, //nolint:mnd
+	"snapshot":  4, //nolint:mnd
+	"":          5, //nolint:mnd
+	"sp":        6, //nolint:mnd
+}
+
+var mavenQualifierAliases = map[string]string{ //nolint:gochecknoglobals
+	"cr":      "rc",
+	"ga":      "",
+	"final":   "",
+	"release": "",
+}
+
+type mavenItem struct {
+	isNumber  bool
+	number    uint64
+	qualifier string
+}
+
+type MavenVersion struct {
+	original string
+	items    []mavenItem
+}
+
+func (v MavenVersion) String() string {
+	return v.original
+}
+
+func (v MavenVersion) IsPrerelease() bool {
+	for _, item := range v.items {
+		if !item.isNumber && mavenQualifierRank(item.qualifier) < mavenQualifierRanks[""] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MavenScheme implements a simplified Maven ComparableVersion (1.0-rc1 < 1.0-SNAPSHOT < 1.0 = 1.0.RELEASE).
+type MavenScheme struct{}
+
+func (MavenScheme) Name() string {
+	return SchemeMaven
+}
+
+//nolint:ireturn
+func (MavenScheme) Parse(value string) (Version, error) {
+	if value == "" || !unicode.IsDigit(rune(value[0])) {
+		return nil, fmt.Errorf("%w %q: not a Maven version", ErrInvalidVersion, value)
+	}
+
+	var (
+		items    []mavenItem
+		token    strings.Builder
+		previous rune
+	)
+
+	flush := func() {
+		if token.Len() > 0 {
+			items = append(items, newMavenItem(token.String()))
+			token.Reset()
+		}
+	}
+
+	for _, char := range strings.ToLower(value) {
+		switch {
+		case char == '.' || char == '-' || char == '_':
+			flush()
+		case !unicode.IsDigit(char) && !unicode.IsLetter(char):
+			return nil, fmt.Errorf("%w %q: unexpected character %q", ErrInvalidVersion, value, char)
+		case token.Len() > 0 && unicode.IsDigit(char) != unicode.IsDigit(previous):
+			flush()
+			token.WriteRune(char)
+		default:
+			token.WriteRune(char)
+		}
+
+		previous = char
+	}
+
+	flush()
+
+	return MavenVersion{original: value, items: items}, nil
+}
+
+//nolint:ireturn
+func (s MavenScheme) Coerce(value string) (Version, error) {
+	return s.Parse(strings.TrimPrefix(strings.TrimPrefix(value, "v"), "V"))
+}
+
+func (MavenScheme) Compare(a, b Version) int {
+	left, right := asMaven(a).items, asMaven(b).items
+	padding := mavenItem{isNumber: false, number: 0, qualifier: ""}
+
+	for index := range max(len(left), len(right)) {
+		leftItem, rightItem := padding, padding
+
+		if index < len(left) {
+			leftItem = left[index]
+		}
+
+		if index < len(right) {
+			rightItem = right[index]
+		}
+
+		if result := compareMavenItems(leftItem, rightItem); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+func (s MavenScheme) ParseConstraint(constraint string) (Constraint, error) {
+	return parseComparatorConstraint(s, constraint)
+}
+
+//nolint:ireturn
+func (s MavenScheme) Latest(versions []Version) Version {
+	return latestOf(s, versions, true)
+}
+
+func newMavenItem(token string) mavenItem {
+	if unicode.IsDigit(rune(token[0])) {
+		return mavenItem{isNumber: true, number: parseNumber(token), qualifier: ""}
+	}
+
+	switch token {
+	case "a":
+		token = "alpha"
+	case "b":
+		token = "beta"
+	case "m":
+		token = "milestone"
+	}
+
+	if alias, ok := mavenQualifierAliases[token]; ok {
+		token = alias
+	}
+
+	return mavenItem{isNumber: false, number: 0, qualifier: token}
+}
+
+// compareMavenItems treats a missing number as 0 and a missing qualifier as a plain release,
+// so 1.0 == 1 == 1.0.RELEASE while numbers always sort above qualifiers.
+func compareMavenItems(left, right mavenItem) int {
+	switch {
+	case left.isNumber && right.isNumber:
+		return cmp.Compare(left.number, right.number)
+	case left.isNumber:
+		if right.qualifier == "" {
+			return cmp.Compare(left.number, 0)
+		}
+
+		return 1
+	case right.isNumber:
+		if left.qualifier == "" {
+			return cmp.Compare(0, right.number)
+		}
+
+		return -1
+	}
+
+	leftRank, rightRank := mavenQualifierRank(left.qualifier), mavenQualifierRank(right.qualifier)
+	if leftRank != rightRank || leftRank != mavenUnknownQualifierRank {
+		return cmp.Compare(leftRank, rightRank)
+	}
+
+	return strings.Compare(left.qualifier, right.qualifier)
+}
+
+func mavenQualifierRank(qualifier string) int {
+	if rank, ok := mavenQualifierRanks[qualifier]; ok {
+		return rank
+	}
+
+	return mavenUnknownQualifierRank
+}
+
+func asMaven(version Version) MavenVersion {
+	mavenVersion, _ := version.(MavenVersion)
+
+	return mavenVersion
+}
@@ -0,0 +1,179 @@
+package versioning
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var pep440Pattern = regexp.MustCompile(`(?i)^v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?:[-_.]?(?P<pre_l>a|b|c|rc|alpha|beta|pre|preview)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?:-(?P<post_n1>[0-9]+)|[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?)?` +
+	`(?:[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+const (
+	pep440PhaseAlpha = iota
+	pep440PhaseBeta
+	pep440PhaseRC
+)
+
+type PEP440Version struct {
+	original string
+	epoch    uint64
+	release  []uint64
+	hasPre   bool
+	prePhase int
+	preNum   uint64
+	hasPost  bool
+	postNum  uint64
+	hasDev   bool
+	devNum   uint64
+	local    string
+}
+
+func (v PEP440Version) String() string {
+	return v.original
+}
+
+func (v PEP440Version) IsPrerelease() bool {
+	return v.hasPre || v.hasDev
+}
+
+// PEP440Scheme implements Python package versions such as 1.0rc1, 1.0.post2 and 2!1.0.dev3.
+type PEP440Scheme struct{}
+
+func (PEP440Scheme) Name() string {
+	return SchemePEP440
+}
+
+//nolint:ireturn
+func (PEP440Scheme) Parse(value string) (Version, error) {
+	match := pep440Pattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return nil, fmt.Errorf("%w %q: not a PEP 440 version", ErrInvalidVersion, value)
+	}
+
+	group := func(name string) string {
+		return match[pep440Pattern.SubexpIndex(name)]
+	}
+
+	version := PEP440Version{
+		original: value,
+		epoch:    parseNumber(group("epoch")),
+		release:  nil,
+		hasPre:   false,
+		prePhase: 0,
+		preNum:   0,
+		hasPost:  false,
+		postNum:  0,
+		hasDev:   false,
+		devNum:   0,
+		local:    strings.ToLower(group("local")),
+	}
+
+	for _, field := range strings.Split(group("release"), ".") {
+		version.release = append(version.release, parseNumber(field))
+	}
+
+	if preLabel := strings.ToLower(group("pre_l")); preLabel != "" {
+		version.hasPre = true
+		version.preNum = parseNumber(group("pre_n"))
+
+		switch preLabel {
+		case "a", "alpha":
+			version.prePhase = pep440PhaseAlpha
+		case "b", "beta":
+			version.prePhase = pep440PhaseBeta
+		default:
+			version.prePhase = pep440PhaseRC
+		}
+	}
+
+	if group("post_n1") != "" || group("post_l") != "" {
+		version.hasPost = true
+		version.postNum = parseNumber(group("post_n1") + group("post_n2"))
+	}
+
+	if group("dev_l") != "" {
+		version.hasDev = true
+		version.devNum = parseNumber(group("dev_n"))
+	}
+
+	return version, nil
+}
+
+//nolint:ireturn
+func (s PEP440Scheme) Coerce(value string) (Version, error) {
+	return s.Parse(value)
+}
+
+func (PEP440Scheme) Compare(a, b Version) int {
+	left, right := asPEP440(a), asPEP440(b)
+
+	if result := cmp.Compare(left.epoch, right.epoch); result != 0 {
+		return result
+	}
+
+	if result := compareReleases(left.release, right.release); result != 0 {
+		return result
+	}
+
+	if result := cmp.Compare(left.preKey(), right.preKey()); result != 0 {
+		return result
+	}
+
+	if result := compareOptional(left.hasPost, left.postNum, right.hasPost, right.postNum, -1); result != 0 {
+		return result
+	}
+
+	if result := compareOptional(left.hasDev, left.devNum, right.hasDev, right.devNum, 1); result != 0 {
+		return result
+	}
+
+	return strings.Compare(left.local, right.local)
+}
+
+func (s PEP440Scheme) ParseConstraint(constraint string) (Constraint, error) {
+	return parseComparatorConstraint(s, constraint)
+}
+
+//nolint:ireturn
+func (s PEP440Scheme) Latest(versions []Version) Version {
+	return latestOf(s, versions, true)
+}
+
+// preKey orders dev-only releases (1.0.dev1) before pre-releases (1.0a1) before final releases.
+func (v PEP440Version) preKey() int64 {
+	switch {
+	case v.hasPre:
+		return int64(v.prePhase)<<32 | int64(v.preNum&0xffffffff) //nolint:mnd
+	case v.hasDev && !v.hasPost:
+		return -1
+	default:
+		return 1 << 62 //nolint:mnd
+	}
+}
+
+// compareOptional compares optional numeric segments; absentOrder is how a missing segment sorts.
+func compareOptional(leftSet bool, left uint64, rightSet bool, right uint64, absentOrder int) int {
+	switch {
+	case leftSet && rightSet:
+		return cmp.Compare(left, right)
+	case leftSet == rightSet:
+		return 0
+	case leftSet:
+		return -absentOrder
+	default:
+		return absentOrder
+	}
+}
+
+func asPEP440(version Version) PEP440Version {
+	pep440Version, _ := version.(PEP440Version)
+
+	return pep440Version
+}
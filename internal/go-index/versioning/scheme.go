@@ -0,0 +1,196 @@
+package versioning
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	SchemeSemver = "semver"
+	SchemeCalVer = "calver"
+	SchemePEP440 = "pep440"
+	SchemeMaven  = "maven"
+	SchemeDebian = "debian"
+)
+
+var (
+	ErrUnknownScheme       = errors.New("unknown version scheme")
+	ErrInvalidVersion      = errors.New("invalid version")
+	ErrInvalidConstraint   = errors.New("invalid version constraint")
+	ErrUnsupportedOperator = errors.New("unsupported operator")
+	ErrEmptyComparator     = errors.New("empty comparator")
+)
+
+type Version interface {
+	String() string
+	IsPrerelease() bool
+}
+
+type Constraint func(Version) bool
+
+type Scheme interface {
+	Name() string
+	Parse(value string) (Version, error)
+	// Coerce parses leniently, e.g. v1.2 -> 1.2.0 for semver.
+	Coerce(value string) (Version, error)
+	Compare(a, b Version) int
+	ParseConstraint(constraint string) (Constraint, error)
+	Latest(versions []Version) Version
+}
+
+//nolint:ireturn
+func Lookup(name string) (Scheme, error) {
+	switch name {
+	case "", SchemeSemver:
+		return SemverScheme{}, nil
+	case SchemeCalVer:
+		return CalVerScheme{}, nil
+	case SchemePEP440:
+		return PEP440Scheme{}, nil
+	case SchemeMaven:
+		return MavenScheme{}, nil
+	case SchemeDebian:
+		return DebianScheme{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownScheme, name)
+	}
+}
+
+// latestOf returns the highest version; with preferStable, pre-releases only win when nothing else exists.
+//
+//nolint:ireturn
+func latestOf(scheme Scheme, versions []Version, preferStable bool) Version {
+	var latest Version
+
+	for _, version := range versions {
+		switch {
+		case latest == nil:
+			latest = version
+		case preferStable && latest.IsPrerelease() != version.IsPrerelease():
+			if latest.IsPrerelease() {
+				latest = version
+			}
+		case scheme.Compare(version, latest) > 0:
+			latest = version
+		}
+	}
+
+	return latest
+}
+
+// parseComparatorConstraint handles schemes without range sugar: comparators (=, !=, >, >=, <, <=)
+// separated by spaces or commas are ANDed and alternatives are separated by "||".
+func parseComparatorConstraint(scheme Scheme, constraint string) (Constraint, error) {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConstraint, ErrEmptyComparator)
+	}
+
+	var alternatives []Constraint
+
+	for _, alternative := range strings.Split(constraint, "||") {
+		tokens := joinOperators(strings.Fields(strings.ReplaceAll(alternative, ",", " ")))
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidConstraint, constraint, ErrEmptyComparator)
+		}
+
+		comparators := make([]Constraint, 0, len(tokens))
+
+		for _, token := range tokens {
+			comparator, err := parseComparator(scheme, token)
+			if err != nil {
+				return nil, fmt.Errorf("%w %q: %w", ErrInvalidConstraint, constraint, err)
+			}
+
+			comparators = append(comparators, comparator)
+		}
+
+		alternatives = append(alternatives, allOf(comparators))
+	}
+
+	return anyOf(alternatives), nil
+}
+
+func parseComparator(scheme Scheme, token string) (Constraint, error) {
+	versionPart := strings.TrimLeft(token, operatorChars)
+	operator := token[:len(token)-len(versionPart)]
+
+	bound, err := scheme.Parse(versionPart)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches func(int) bool
+
+	switch operator {
+	case "", "=", "==":
+		matches = func(result int) bool { return result == 0 }
+	case "!=":
+		matches = func(result int) bool { return result != 0 }
+	case ">":
+		matches = func(result int) bool { return result > 0 }
+	case ">=":
+		matches = func(result int) bool { return result >= 0 }
+	case "<":
+		matches = func(result int) bool { return result < 0 }
+	case "<=":
+		matches = func(result int) bool { return result <= 0 }
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedOperator, operator)
+	}
+
+	return func(version Version) bool { return matches(scheme.Compare(version, bound)) }, nil
+}
+
+func allOf(constraints []Constraint) Constraint {
+	return func(version Version) bool {
+		for _, constraint := range constraints {
+			if !constraint(version) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+func anyOf(constraints []Constraint) Constraint {
+	return func(version Version) bool {
+		for _, constraint := range constraints {
+			if constraint(version) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func compareReleases(left, right []uint64) int {
+	for index := range max(len(left), len(right)) {
+		var leftPart, rightPart uint64
+
+		if index < len(left) {
+			leftPart = left[index]
+		}
+
+		if index < len(right) {
+			rightPart = right[index]
+		}
+
+		if result := cmp.Compare(leftPart, rightPart); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+func parseNumber(value string) uint64 {
+	number, _ := strconv.ParseUint(value, 10, 64)
+
+	return number
+}
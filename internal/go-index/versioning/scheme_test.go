@@ -0,0 +1,190 @@
+package versioning_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+)
+
+func TestSchemeCompare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scheme   string
+		left     string
+		right    string
+		expected int
+	}{
+		{versioning.SchemeSemver, "1.0.0", "1.0.1", -1},
+		{versioning.SchemeSemver, "2.0.0-beta.1", "2.0.0", -1},
+		{versioning.SchemeCalVer, "2024.10.3", "2024.9.30", 1},
+		{versioning.SchemeCalVer, "2024.10", "2024.10.0", 0},
+		{versioning.SchemeCalVer, "2024.10.3-beta", "2024.10.3", -1},
+		{versioning.SchemePEP440, "1.0rc1", "1.0", -1},
+		{versioning.SchemePEP440, "1.0.dev1", "1.0a1", -1},
+		{versioning.SchemePEP440, "1.0a2", "1.0b1", -1},
+		{versioning.SchemePEP440, "1.0.post2", "1.0", 1},
+		{versioning.SchemePEP440, "1.0.post1", "1.0.post2", -1},
+		{versioning.SchemePEP440, "1.0", "1.0.0", 0},
+		{versioning.SchemePEP440, "1!0.1", "2.0", 1},
+		{versioning.SchemePEP440, "1.0-1", "1.0.post1", 0},
+		{versioning.SchemeMaven, "1.0-SNAPSHOT", "1.0", -1},
+		{versioning.SchemeMaven, "1.0.RELEASE", "1.0", 0},
+		{versioning.SchemeMaven, "1.0-alpha-1", "1.0-beta-1", -1},
+		{versioning.SchemeMaven, "1.0-rc1", "1.0-SNAPSHOT", -1},
+		{versioning.SchemeMaven, "1.0-SNAPSHOT", "1.0-cr2", 1},
+		{versioning.SchemeMaven, "1.0-milestone-1", "1.0-SNAPSHOT", -1},
+		{versioning.SchemeMaven, "1.0.1", "1.0-sp1", 1},
+		{versioning.SchemeMaven, "1.0-sp1", "1.0", 1},
+		{versioning.SchemeMaven, "1.10", "1.9", 1},
+		{versioning.SchemeDebian, "1.0~rc1", "1.0", -1},
+		{versioning.SchemeDebian, "1.0-1", "1.0-2", -1},
+		{versioning.SchemeDebian, "1:0.9", "2.0", 1},
+		{versioning.SchemeDebian, "1.0a", "1.0+", -1},
+		{versioning.SchemeDebian, "1.10", "1.9", 1},
+		{versioning.SchemeDebian, "1.0", "1.0", 0},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.scheme+"/"+testCase.left+"/"+testCase.right, func(t *testing.T) {
+			t.Parallel()
+
+			scheme := mustLookup(t, testCase.scheme)
+			left := mustParse(t, scheme, testCase.left)
+			right := mustParse(t, scheme, testCase.right)
+
+			if got := scheme.Compare(left, right); got != testCase.expected {
+				t.Errorf("%s Compare(%q, %q) = %d; want %d", testCase.scheme, testCase.left, testCase.right, got, testCase.expected)
+			}
+
+			if got := scheme.Compare(right, left); got != -testCase.expected {
+				t.Errorf("%s Compare(%q, %q) = %d; want %d", testCase.scheme, testCase.right, testCase.left, got, -testCase.expected)
+			}
+		})
+	}
+}
+
+func TestSchemeParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scheme string
+		value  string
+	}{
+		{versioning.SchemeSemver, "v1.2"},
+		{versioning.SchemeCalVer, "1.2.3"},
+		{versioning.SchemeCalVer, "2024.x"},
+		{versioning.SchemePEP440, "1.0-foo"},
+		{versioning.SchemeMaven, "SNAPSHOT"},
+		{versioning.SchemeDebian, "a1.0"},
+		{versioning.SchemeDebian, "x:1.0"},
+	}
+
+	for _, testCase := range tests {
+		scheme := mustLookup(t, testCase.scheme)
+
+		if _, err := scheme.Parse(testCase.value); !errors.Is(err, versioning.ErrInvalidVersion) {
+			t.Errorf("%s Parse(%q) returned %v; want %v", testCase.scheme, testCase.value, err, versioning.ErrInvalidVersion)
+		}
+	}
+}
+
+func TestSchemeLatest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scheme   string
+		versions []string
+		expected string
+	}{
+		{versioning.SchemeSemver, []string{"1.0.0", "2.0.0-beta.1", "1.5.0"}, "2.0.0-beta.1"},
+		{versioning.SchemeCalVer, []string{"2024.9.1", "2024.10.3", "2024.11.0-rc"}, "2024.10.3"},
+		{versioning.SchemePEP440, []string{"1.0", "1.1rc1", "1.0.post2"}, "1.0.post2"},
+		{versioning.SchemePEP440, []string{"1.1rc1", "1.1b2"}, "1.1rc1"},
+		{versioning.SchemeMaven, []string{"1.0", "1.1-SNAPSHOT", "1.0.1.RELEASE"}, "1.0.1.RELEASE"},
+		{versioning.SchemeDebian, []string{"1.0-1", "1.1~rc1", "1.0-2"}, "1.0-2"},
+	}
+
+	for _, testCase := range tests {
+		scheme := mustLookup(t, testCase.scheme)
+		versions := make([]versioning.Version, 0, len(testCase.versions))
+
+		for _, value := range testCase.versions {
+			versions = append(versions, mustParse(t, scheme, value))
+		}
+
+		if got := scheme.Latest(versions).String(); got != testCase.expected {
+			t.Errorf("%s Latest(%v) = %q; want %q", testCase.scheme, testCase.versions, got, testCase.expected)
+		}
+	}
+}
+
+func TestComparatorConstraint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scheme     string
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{versioning.SchemeCalVer, ">=2024.1, <2025", "2024.10.3", true},
+		{versioning.SchemeCalVer, ">=2024.1 <2025", "2025.1.0", false},
+		{versioning.SchemePEP440, ">=1.0,!=1.1", "1.1.0", false},
+		{versioning.SchemePEP440, "<1.0 || >=2.0", "2.0rc1", false},
+		{versioning.SchemeMaven, ">1.0-SNAPSHOT", "1.0", true},
+		{versioning.SchemeDebian, "<= 1.0-1", "1.0~rc1", true},
+	}
+
+	for _, testCase := range tests {
+		scheme := mustLookup(t, testCase.scheme)
+
+		constraint, err := scheme.ParseConstraint(testCase.constraint)
+		if err != nil {
+			t.Fatalf("%s ParseConstraint(%q) returned an error: %v", testCase.scheme, testCase.constraint, err)
+		}
+
+		if got := constraint(mustParse(t, scheme, testCase.version)); got != testCase.expected {
+			t.Errorf("%s constraint %q matches %q = %v; want %v",
+				testCase.scheme, testCase.constraint, testCase.version, got, testCase.expected)
+		}
+	}
+
+	scheme := mustLookup(t, versioning.SchemeMaven)
+	if _, err := scheme.ParseConstraint("^1.0"); !errors.Is(err, versioning.ErrInvalidConstraint) {
+		t.Errorf("ParseConstraint returned %v; want %v", err, versioning.ErrInvalidConstraint)
+	}
+}
+
+func TestLookupUnknownScheme(t *testing.T) {
+	t.Parallel()
+
+	if _, err := versioning.Lookup("romver"); !errors.Is(err, versioning.ErrUnknownScheme) {
+		t.Errorf("Lookup returned %v; want %v", err, versioning.ErrUnknownScheme)
+	}
+}
+
+//nolint:ireturn
+func mustLookup(t *testing.T, name string) versioning.Scheme {
+	t.Helper()
+
+	scheme, err := versioning.Lookup(name)
+	if err != nil {
+		t.Fatalf("Lookup(%q) returned an error: %v", name, err)
+	}
+
+	return scheme
+}
+
+//nolint:ireturn
+func mustParse(t *testing.T, scheme versioning.Scheme, value string) versioning.Version {
+	t.Helper()
+
+	version, err := scheme.Parse(value)
+	if err != nil {
+		t.Fatalf("%s Parse(%q) returned an error: %v", scheme.Name(), value, err)
+	}
+
+	return version
+}
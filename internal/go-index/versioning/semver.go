@@ -0,0 +1,69 @@
+package versioning
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+)
+
+type SemverVersion struct {
+	semver.Version
+}
+
+func (v SemverVersion) IsPrerelease() bool {
+	return len(v.Pre) > 0
+}
+
+type SemverScheme struct{}
+
+func (SemverScheme) Name() string {
+	return SchemeSemver
+}
+
+//nolint:ireturn
+func (SemverScheme) Parse(value string) (Version, error) {
+	version, err := semver.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidVersion, value, err)
+	}
+
+	return SemverVersion{Version: version}, nil
+}
+
+//nolint:ireturn
+func (SemverScheme) Coerce(value string) (Version, error) {
+	version, err := semver.ParseTolerant(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidVersion, value, err)
+	}
+
+	return SemverVersion{Version: version}, nil
+}
+
+func (SemverScheme) Compare(a, b Version) int {
+	return asSemver(a).Compare(asSemver(b))
+}
+
+func (SemverScheme) ParseConstraint(constraint string) (Constraint, error) {
+	versionRange, err := ParseSemverConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(version Version) bool { return versionRange(asSemver(version)) }, nil
+}
+
+// Latest keeps the historical semver behaviour of returning the highest version, pre-releases included.
+//
+//nolint:ireturn
+func (s SemverScheme) Latest(versions []Version) Version {
+	return latestOf(s, versions, false)
+}
+
+func asSemver(version Version) semver.Version {
+	if semverVersion, ok := version.(SemverVersion); ok {
+		return semverVersion.Version
+	}
+
+	return semver.Version{Major: 0, Minor: 0, Patch: 0, Pre: nil, Build: nil}
+}
@@ -0,0 +1,233 @@
+package versioning
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+const (
+	majorOnlyParts = 1
+	majorMinorPart = 2
+	fullParts      = 3
+	operatorChars  = "=<>!^~"
+)
+
+type partialVersion struct {
+	major, minor, patch uint64
+	parts               int
+	pre                 []semver.PRVersion
+}
+
+func (pv partialVersion) floor() semver.Version {
+	return semver.Version{Major: pv.major, Minor: pv.minor, Patch: pv.patch, Pre: pv.pre, Build: nil}
+}
+
+// ceiling returns the lowest version above everything the partial version matches, e.g. 1.4 -> 1.5.0.
+func (pv partialVersion) ceiling() semver.Version {
+	switch pv.parts {
+	case majorOnlyParts:
+		return newVersion(pv.major+1, 0, 0)
+	case majorMinorPart:
+		return newVersion(pv.major, pv.minor+1, 0)
+	default:
+		return newVersion(pv.major, pv.minor, pv.patch+1)
+	}
+}
+
+// ParseSemverConstraint builds a semver.Range from npm style constraints such as "^1.4", "~2.3.0",
+// ">=1.0.0 <2.0.0", "1.x" or "1.2 || ^3". Pre-releases only match when the constraint names one.
+func ParseSemverConstraint(constraint string) (semver.Range, error) {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConstraint, ErrEmptyComparator)
+	}
+
+	var result semver.Range
+
+	for _, alternative := range strings.Split(constraint, "||") {
+		alternativeRange, err := parseSemverAlternative(alternative)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidConstraint, constraint, err)
+		}
+
+		if result == nil {
+			result = alternativeRange
+		} else {
+			result = result.OR(alternativeRange)
+		}
+	}
+
+	if !strings.Contains(constraint, "-") {
+		result = result.AND(func(version semver.Version) bool { return len(version.Pre) == 0 })
+	}
+
+	return result, nil
+}
+
+func parseSemverAlternative(alternative string) (semver.Range, error) {
+	tokens := joinOperators(strings.Fields(alternative))
+	if len(tokens) == 0 {
+		return nil, ErrEmptyComparator
+	}
+
+	result := semver.Range(matchAll)
+
+	for _, token := range tokens {
+		comparator, err := parseSemverComparator(token)
+		if err != nil {
+			return nil, err
+		}
+
+		result = result.AND(comparator)
+	}
+
+	return result, nil
+}
+
+// joinOperators merges operators written apart from their version, e.g. ">= 1.0.0".
+func joinOperators(tokens []string) []string {
+	joined := make([]string, 0, len(tokens))
+
+	for index := 0; index < len(tokens); index++ {
+		token := tokens[index]
+		if strings.Trim(token, operatorChars) == "" && index+1 < len(tokens) {
+			index++
+			token += tokens[index]
+		}
+
+		joined = append(joined, token)
+	}
+
+	return joined
+}
+
+//nolint:cyclop
+func parseSemverComparator(token string) (semver.Range, error) {
+	versionPart := strings.TrimLeft(token, operatorChars)
+	operator := token[:len(token)-len(versionPart)]
+
+	version, err := parsePartialVersion(strings.TrimPrefix(versionPart, "v"))
+	if err != nil {
+		return nil, err
+	}
+
+	floor, ceiling := version.floor(), version.ceiling()
+	exact := matchPartial(version)
+
+	switch operator {
+	case "", "=", "==":
+		return exact, nil
+	case "!=":
+		return func(v semver.Version) bool { return !exact(v) }, nil
+	case ">":
+		if version.parts < fullParts {
+			return func(v semver.Version) bool { return v.GTE(ceiling) }, nil
+		}
+
+		return func(v semver.Version) bool { return v.GT(floor) }, nil
+	case ">=":
+		return func(v semver.Version) bool { return v.GTE(floor) }, nil
+	case "<":
+		return func(v semver.Version) bool { return v.LT(floor) }, nil
+	case "<=":
+		if version.parts < fullParts {
+			return func(v semver.Version) bool { return v.LT(ceiling) }, nil
+		}
+
+		return func(v semver.Version) bool { return v.LTE(floor) }, nil
+	case "~":
+		return between(floor, tildeCeiling(version)), nil
+	case "^":
+		return between(floor, caretCeiling(version)), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedOperator, operator)
+	}
+}
+
+func matchAll(semver.Version) bool {
+	return true
+}
+
+func matchPartial(version partialVersion) semver.Range {
+	switch version.parts {
+	case 0:
+		return matchAll
+	case fullParts:
+		floor := version.floor()
+
+		return func(v semver.Version) bool { return v.EQ(floor) }
+	default:
+		return between(version.floor(), version.ceiling())
+	}
+}
+
+func between(floor, ceiling semver.Version) semver.Range {
+	return func(v semver.Version) bool { return v.GTE(floor) && v.LT(ceiling) }
+}
+
+func tildeCeiling(version partialVersion) semver.Version {
+	if version.parts <= majorOnlyParts {
+		return newVersion(version.major+1, 0, 0)
+	}
+
+	return newVersion(version.major, version.minor+1, 0)
+}
+
+func caretCeiling(version partialVersion) semver.Version {
+	switch {
+	case version.major > 0 || version.parts <= majorOnlyParts:
+		return newVersion(version.major+1, 0, 0)
+	case version.minor > 0 || version.parts == majorMinorPart:
+		return newVersion(0, version.minor+1, 0)
+	default:
+		return newVersion(0, 0, version.patch+1)
+	}
+}
+
+func newVersion(major, minor, patch uint64) semver.Version {
+	return semver.Version{Major: major, Minor: minor, Patch: patch, Pre: nil, Build: nil}
+}
+
+func parsePartialVersion(input string) (partialVersion, error) {
+	var version partialVersion
+
+	if strings.Contains(input, "-") {
+		full, err := semver.Parse(input)
+		if err != nil {
+			return version, fmt.Errorf("%w %q: %w", ErrInvalidVersion, input, err)
+		}
+
+		version.major, version.minor, version.patch = full.Major, full.Minor, full.Patch
+		version.parts, version.pre = fullParts, full.Pre
+
+		return version, nil
+	}
+
+	core, _, _ := strings.Cut(input, "+")
+	fields := strings.Split(core, ".")
+
+	if len(fields) > fullParts {
+		return version, fmt.Errorf("%w: %q", ErrInvalidVersion, input)
+	}
+
+	values := []*uint64{&version.major, &version.minor, &version.patch}
+
+	for index, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			break
+		}
+
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return version, fmt.Errorf("%w %q: %w", ErrInvalidVersion, input, err)
+		}
+
+		*values[index] = value
+		version.parts = index + 1
+	}
+
+	return version, nil
+}
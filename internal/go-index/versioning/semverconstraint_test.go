@@ -0,0 +1,72 @@
+package versioning_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/mauhlik/go-index/internal/go-index/versioning"
+)
+
+func TestParseSemverConstraint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"^1.4", "1.4.0", true},
+		{"^1.4", "1.9.3", true},
+		{"^1.4", "2.0.0", false},
+		{"^1.4", "1.3.9", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"~2.3.0", "2.3.7", true},
+		{"~2.3.0", "2.4.0", false},
+		{"~2", "2.9.0", true},
+		{">=1.0.0 <2.0.0", "1.5.0", true},
+		{">=1.0.0 <2.0.0", "2.0.0", false},
+		{">= 1.0.0 < 2.0.0", "1.0.0", true},
+		{"1.x", "1.7.1", true},
+		{"1.2.*", "1.3.0", false},
+		{"1.2", "1.2.5", true},
+		{"*", "3.0.0", true},
+		{">1.4", "1.4.9", false},
+		{">1.4", "1.5.0", true},
+		{"<=1.4", "1.4.9", true},
+		{"!=1.0.0", "1.0.0", false},
+		{"=v1.0.0", "1.0.0", true},
+		{"^1.0.0 || ^3.0.0", "3.1.0", true},
+		{"^1.0.0 || ^3.0.0", "2.1.0", false},
+		{"^1.0.0", "1.1.0-beta.1", false},
+		{">=1.1.0-beta.1", "1.1.0-beta.2", true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.constraint+"/"+testCase.version, func(t *testing.T) {
+			t.Parallel()
+
+			versionRange, err := versioning.ParseSemverConstraint(testCase.constraint)
+			if err != nil {
+				t.Fatalf("ParseSemverConstraint(%q) returned an error: %v", testCase.constraint, err)
+			}
+
+			got := versionRange(semver.MustParse(testCase.version))
+			if got != testCase.expected {
+				t.Errorf("ParseSemverConstraint(%q)(%s) = %v; want %v", testCase.constraint, testCase.version, got, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestParseSemverConstraintInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, constraint := range []string{"", "^", "abc", ">>1.0.0", "1.2.3.4", "^1.0.0 ||"} {
+		if _, err := versioning.ParseSemverConstraint(constraint); !errors.Is(err, versioning.ErrInvalidConstraint) {
+			t.Errorf("ParseSemverConstraint(%q) returned %v; want %v", constraint, err, versioning.ErrInvalidConstraint)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Package client is a typed client for the go-index HTTP API.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+
+	skippedVersionsHeader = "X-Skipped-Versions"
+)
+
+var ErrInvalidBaseURL = errors.New("base URL must be an absolute http or https URL")
+
+// Options configure New. Token and Username/Password are mutually exclusive ways to authenticate.
+type Options struct {
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Token is sent as a bearer token, e.g. an API token or an OIDC ID token.
+	Token    string
+	Username string
+	Password string
+	// MaxRetries is how often a request failing with a network error, 429, 502, 503 or 504 is retried,
+	// default 3; a negative value disables retries.
+	MaxRetries int
+	// MinBackoff and MaxBackoff bound the jittered exponential delay between retries.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	UserAgent  string
+}
+
+type Client struct {
+	baseURL *url.URL
+	options Options
+}
+
+type Resolution struct {
+	Version string
+	// Skipped lists the versions the server ignored because they do not follow the repository's scheme.
+	Skipped []string
+}
+
+type File struct {
+	Path         string            `json:"path"`
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Size         int64             `json:"size"`
+	LastModified time.Time         `json:"lastModified"`
+	ContentType  string            `json:"contentType"`
+	ETag         string            `json:"etag,omitempty"`
+	Checksums    map[string]string `json:"checksums,omitempty"`
+}
+
+type VersionDetails struct {
+	Module   string `json:"module"`
+	Artifact string `json:"artifact"`
+	Version  string `json:"version"`
+	Files    []File `json:"files"`
+}
+
+// New returns a client for the server at baseURL, such as https://index.example.com.
+func New(baseURL string, options Options) (*Client, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidBaseURL, baseURL)
+	}
+
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+
+	if options.MaxRetries == 0 {
+		options.MaxRetries = DefaultMaxRetries
+	}
+
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = DefaultMinBackoff
+	}
+
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = DefaultMaxBackoff
+	}
+
+	return &Client{baseURL: parsed, options: options}, nil
+}
+
+func (c *Client) Versions(ctx context.Context, repository, module, artifact string) ([]string, error) {
+	var versions []string
+
+	if _, err := c.getJSON(ctx, artifactPath(repository, module, artifact, "versions"), nil, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (c *Client) Latest(ctx context.Context, repository, module, artifact string) (*Resolution, error) {
+	return c.resolution(ctx, artifactPath(repository, module, artifact, "versions", "latest"), nil)
+}
+
+// Resolve returns the highest version matching constraint, in the syntax of the repository's scheme.
+func (c *Client) Resolve(ctx context.Context, repository, module, artifact, constraint string) (*Resolution, error) {
+	return c.resolution(ctx, artifactPath(repository, module, artifact, "versions", "resolve"),
+		url.Values{"constraint": {constraint}})
+}
+
+// Version returns the files of a version.
+func (c *Client) Version(ctx context.Context, repository, module, artifact, version string) (*VersionDetails, error) {
+	var details VersionDetails
+
+	if _, err := c.getJSON(ctx, artifactPath(repository, module, artifact, "versions", version), nil,
+		&details); err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// Download opens a file of a version; an empty file selects the version's only file. Redirects to
+// presigned URLs are followed. The caller must close the returned body.
+func (c *Client) Download(ctx context.Context, repository, module, artifact, version,
+	file string) (io.ReadCloser, error) {
+	elems := []string{"versions", version, "download"}
+	if file != "" {
+		elems = append(elems, file)
+	}
+
+	response, err := c.do(ctx, artifactPath(repository, module, artifact, elems...), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Body, nil
+}
+
+func (c *Client) resolution(ctx context.Context, path string, query url.Values) (*Resolution, error) {
+	var version string
+
+	header, err := c.getJSON(ctx, path, query, &version)
+	if err != nil {
+		return nil, err
+	}
+
+	resolution := &Resolution{Version: version, Skipped: nil}
+	if skipped := header.Get(skippedVersionsHeader); skipped != "" {
+		resolution.Skipped = strings.Split(skipped, ",")
+	}
+
+	return resolution, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, target interface{}) (http.Header, error) {
+	response, err := c.do(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(target); err != nil {
+		return nil, fmt.Errorf("failed to decode response of %s: %w", path, err)
+	}
+
+	return response.Header, nil
+}
+
+// do sends a GET request, retrying transient failures, and returns the successful response.
+func (c *Client) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	target := *c.baseURL
+	target.Path += path
+	target.RawPath = c.baseURL.EscapedPath() + escapePath(path)
+	target.RawQuery = query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		response, err := c.send(ctx, target.String())
+		if err == nil && response.StatusCode < http.StatusMultipleChoices {
+			return response, nil
+		}
+
+		var retryAfter time.Duration
+
+		if err == nil {
+			err = readError(response)
+			response.Body.Close()
+
+			retryAfter = parseRetryAfter(response.Header.Get("Retry-After"))
+		}
+
+		if attempt >= c.options.MaxRetries || !retryable(ctx, err) {
+			return nil, err
+		}
+
+		timer := time.NewTimer(max(retryAfter, c.backoff(attempt)))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, target string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	if c.options.UserAgent != "" {
+		request.Header.Set("User-Agent", c.options.UserAgent)
+	}
+
+	switch {
+	case c.options.Token != "":
+		request.Header.Set("Authorization", "Bearer "+c.options.Token)
+	case c.options.Username != "":
+		request.SetBasicAuth(c.options.Username, c.options.Password)
+	}
+
+	response, err := c.options.HTTPClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return response, nil
+}
+
+// backoff doubles the delay with every attempt up to MaxBackoff, randomizing its upper half so clients
+// that failed together do not retry together.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.options.MinBackoff << min(attempt, 30)
+	if delay <= 0 || delay > c.options.MaxBackoff {
+		delay = c.options.MaxBackoff
+	}
+
+	return delay/2 + rand.N(delay/2+1) //nolint:gosec
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return errors.Is(apiErr, ErrUnavailable) || errors.Is(apiErr, ErrTimeout)
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Only network failures are repeated; a request that cannot be built or sent, such as one with an
+	// unsupported URL scheme, fails the same way every time.
+	var (
+		opErr  *net.OpError
+		netErr net.Error
+	)
+
+	return errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+func artifactPath(repository, module, artifact string, elems ...string) string {
+	return "/" + strings.Join(append([]string{"api", repository, module, artifact}, elems...), "/")
+}
+
+// escapePath escapes each element of path, so versions such as 1.0.0+build reach the server intact.
+func escapePath(path string) string {
+	elems := strings.Split(path, "/")
+	for index, elem := range elems {
+		elems[index] = url.PathEscape(elem)
+	}
+
+	return strings.Join(elems, "/")
+}
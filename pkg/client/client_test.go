@@ -0,0 +1,252 @@
+package client_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mauhlik/go-index/config"
+	"github.com/mauhlik/go-index/internal/go-index/metrics"
+	"github.com/mauhlik/go-index/internal/go-index/server"
+	"github.com/mauhlik/go-index/pkg/client"
+	"github.com/sirupsen/logrus"
+)
+
+const testToken = "ci-token"
+
+// newTestServer runs the real router over a local repository named files, readable with testToken only.
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+
+	for _, version := range []string{"1.0.0", "1.2.0", "2.0.0", "nightly"} {
+		filename := filepath.Join(dir, "fe", "app1", "app1-"+version+".txt")
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+
+		if err := os.WriteFile(filename, []byte("content "+version), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", filename, err)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(testToken))
+
+	handler, err := server.NewHandler(&config.Config{
+		Repositories: []config.RepositoryConfig{{
+			Name:     "files",
+			Provider: "local",
+			Access:   config.AccessConfig{Anonymous: "none", Roles: map[string]string{"ci": "read"}},
+		}},
+		Providers: map[string]interface{}{"local": config.LocalProviderConfig{Type: "local", Path: dir}},
+		Auth: config.AuthConfig{Users: []config.UserConfig{
+			{Name: "ci", TokenHashes: []config.Secret{config.Secret("sha256:" + hex.EncodeToString(sum[:]))}},
+		}},
+	}, metrics.New(), logrus.New())
+	if err != nil {
+		t.Fatalf("NewHandler returned an error: %v", err)
+	}
+
+	t.Cleanup(func() { handler.Close() })
+
+	var root http.Handler = handler
+	if wrap != nil {
+		root = wrap(handler)
+	}
+
+	testServer := httptest.NewServer(root)
+	t.Cleanup(testServer.Close)
+
+	return testServer
+}
+
+func newClient(t *testing.T, baseURL string, options client.Options) *client.Client {
+	t.Helper()
+
+	apiClient, err := client.New(baseURL, options)
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+
+	return apiClient
+}
+
+func TestClientQueries(t *testing.T) {
+	t.Parallel()
+
+	testServer := newTestServer(t, nil)
+	apiClient := newClient(t, testServer.URL, client.Options{Token: testToken})
+	ctx := context.Background()
+
+	versions, err := apiClient.Versions(ctx, "files", "fe", "app1")
+	if err != nil || !slices.Contains(versions, "1.2.0") {
+		t.Errorf("Versions returned %v, %v; want the published versions", versions, err)
+	}
+
+	latest, err := apiClient.Latest(ctx, "files", "fe", "app1")
+	if err != nil || latest.Version != "2.0.0" || !slices.Equal(latest.Skipped, []string{"nightly"}) {
+		t.Errorf("Latest returned %+v, %v; want 2.0.0 with nightly skipped", latest, err)
+	}
+
+	resolved, err := apiClient.Resolve(ctx, "files", "fe", "app1", "^1.0.0")
+	if err != nil || resolved.Version != "1.2.0" {
+		t.Errorf("Resolve returned %+v, %v; want 1.2.0", resolved, err)
+	}
+
+	details, err := apiClient.Version(ctx, "files", "fe", "app1", "1.2.0")
+	if err != nil || len(details.Files) != 1 || details.Files[0].Name != "app1-1.2.0.txt" {
+		t.Fatalf("Version returned %+v, %v; want the single file of 1.2.0", details, err)
+	}
+
+	body, err := apiClient.Download(ctx, "files", "fe", "app1", "1.2.0", "")
+	if err != nil {
+		t.Fatalf("Download returned an error: %v", err)
+	}
+	defer body.Close()
+
+	if content, _ := io.ReadAll(body); string(content) != "content 1.2.0" {
+		t.Errorf("Download returned %q; want the file content", content)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	t.Parallel()
+
+	testServer := newTestServer(t, nil)
+	authorized := newClient(t, testServer.URL, client.Options{Token: testToken})
+	ctx := context.Background()
+
+	_, err := newClient(t, testServer.URL, client.Options{}).Versions(ctx, "files", "fe", "app1")
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Versions without a token returned %v; want %v", err, client.ErrUnauthorized)
+	}
+
+	_, err = authorized.Version(ctx, "files", "fe", "app1", "9.9.9")
+
+	var apiErr *client.Error
+	if !errors.Is(err, client.ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Code != "version_not_found" ||
+		apiErr.Message == "" || apiErr.RequestID == "" {
+		t.Errorf("Version of a missing version returned %#v; want %v with the server code, message and request ID",
+			err, client.ErrNotFound)
+	}
+
+	if _, err := authorized.Resolve(ctx, "files", "fe", "app1", "not a constraint"); !errors.Is(err, client.ErrBadRequest) {
+		t.Errorf("Resolve with an invalid constraint returned %v; want %v", err, client.ErrBadRequest)
+	}
+
+	if _, err := client.New("index.example.com", client.Options{}); !errors.Is(err, client.ErrInvalidBaseURL) {
+		t.Errorf("New with a relative URL returned %v; want %v", err, client.ErrInvalidBaseURL)
+	}
+}
+
+// flaky answers 503 to the first failures requests.
+func flaky(failures int32, attempts *atomic.Int32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if attempts.Add(1) <= failures {
+				http.Error(writer, `{"error":"provider unavailable"}`, http.StatusServiceUnavailable)
+
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+
+	testServer := newTestServer(t, flaky(2, &attempts))
+	options := client.Options{Token: testToken, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	latest, err := newClient(t, testServer.URL, options).Latest(context.Background(), "files", "fe", "app1")
+	if err != nil || latest.Version != "2.0.0" || attempts.Load() != 3 {
+		t.Errorf("Latest returned %+v, %v after %d attempts; want 2.0.0 after 3", latest, err, attempts.Load())
+	}
+
+	attempts.Store(-10)
+	options.MaxRetries = 1
+
+	_, err = newClient(t, testServer.URL, options).Latest(context.Background(), "files", "fe", "app1")
+	if !errors.Is(err, client.ErrUnavailable) {
+		t.Errorf("Latest returned %v once retries ran out; want %v", err, client.ErrUnavailable)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	options = client.Options{Token: testToken, MinBackoff: time.Hour, MaxBackoff: time.Hour}
+
+	_, err = newClient(t, testServer.URL, options).Latest(ctx, "files", "fe", "app1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Latest returned %v; want the context deadline to end the backoff", err)
+	}
+}
+
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestClientRetriesOnlyNetworkErrors(t *testing.T) {
+	t.Parallel()
+
+	testServer := newTestServer(t, nil)
+
+	testCases := []struct {
+		name     string
+		err      error
+		attempts int32
+	}{
+		{"network error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, 2},
+		{"cancelled", context.Canceled, 1},
+		{"deadline", context.DeadlineExceeded, 1},
+		{"request error", errors.New("unsupported request"), 1},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+
+			transport := roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				if attempts.Add(1) == 1 {
+					return nil, testCase.err
+				}
+
+				return http.DefaultTransport.RoundTrip(request)
+			})
+
+			options := client.Options{
+				Token: testToken, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetries: 3,
+				HTTPClient: &http.Client{Transport: transport},
+			}
+
+			_, err := newClient(t, testServer.URL, options).Latest(context.Background(), "files", "fe", "app1")
+			if attempts.Load() != testCase.attempts || (testCase.attempts == 1) == (err == nil) {
+				t.Errorf("Latest returned %v after %d attempts; want %d", err, attempts.Load(), testCase.attempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by *Error through errors.Is, one per class of server response.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("authentication required")
+	ErrForbidden    = errors.New("access denied")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrAmbiguous    = errors.New("ambiguous download")
+	ErrTimeout      = errors.New("server timed out")
+	ErrUnavailable  = errors.New("server unavailable")
+	ErrServer       = errors.New("server error")
+)
+
+const (
+	// maxErrorBody bounds how much of an error response is read.
+	maxErrorBody = 64 << 10
+
+	requestIDHeader = "X-Request-ID"
+)
+
+// Error is a response with a non-success status. Code, Message and RequestID are reported by the server;
+// Code is empty for servers that predate structured errors.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("go-index: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("go-index: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+func (e *Error) Is(target error) bool {
+	return errors.Is(statusError(e.StatusCode), target)
+}
+
+func statusError(status int) error {
+	switch {
+	case status == http.StatusBadRequest:
+		return ErrBadRequest
+	case status == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case status == http.StatusForbidden:
+		return ErrForbidden
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusConflict:
+		return ErrConflict
+	case status == http.StatusMultipleChoices:
+		return ErrAmbiguous
+	case status == http.StatusGatewayTimeout:
+		return ErrTimeout
+	case status == http.StatusServiceUnavailable || status == http.StatusBadGateway ||
+		status == http.StatusTooManyRequests:
+		return ErrUnavailable
+	case status >= http.StatusInternalServerError:
+		return ErrServer
+	default:
+		return nil
+	}
+}
+
+// readError builds an *Error from a failed response, reading a JSON {"code", "message", "requestId"}
+// body, a legacy JSON {"error": ...} body or a plain text one.
+func readError(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+
+	var payload struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		RequestID string `json:"requestId"`
+		Error     string `json:"error"`
+	}
+
+	result := &Error{
+		StatusCode: response.StatusCode,
+		Code:       "",
+		Message:    strings.TrimSpace(string(body)),
+		RequestID:  response.Header.Get(requestIDHeader),
+	}
+
+	if json.Unmarshal(body, &payload) != nil {
+		return result
+	}
+
+	switch {
+	case payload.Code != "":
+		result.Code = payload.Code
+		result.Message = payload.Message
+	case payload.Error != "":
+		result.Message = payload.Error
+	}
+
+	if payload.RequestID != "" {
+		result.RequestID = payload.RequestID
+	}
+
+	return result
+}